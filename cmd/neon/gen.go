@@ -0,0 +1,284 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const neonImportPath = "github.com/sri-shubham/neon"
+
+// endpointMethods maps endpoint marker types to the expression of the method they serve
+var endpointMethods = map[string]string{
+	"Get":     "http.MethodGet",
+	"Head":    "http.MethodHead",
+	"Put":     "http.MethodPut",
+	"Post":    "http.MethodPost",
+	"Patch":   "http.MethodPatch",
+	"Delete":  "http.MethodDelete",
+	"Options": "http.MethodOptions",
+	"Trace":   "http.MethodTrace",
+	"Connect": "http.MethodConnect",
+	"Any":     "neon.MethodAny",
+}
+
+// service : A struct embedding neon.Module
+type service struct {
+	typeName   string
+	base       string
+	version    string
+	host       string
+	middleware string
+	mtls       string
+	endpoints  []endpoint
+}
+
+// endpoint : An endpoint field of a service
+type endpoint struct {
+	method     string
+	field      string
+	handler    string
+	url        string
+	version    string
+	host       string
+	middleware string
+	mtls       string
+}
+
+// generate parses the package in dir and returns the source of a file
+// registering each of its services; skip names a file to leave out, usually
+// the previous output
+func generate(dir, skip string) ([]byte, error) {
+	pkgName, services, err := parseServices(dir, skip)
+	if err != nil {
+		return nil, err
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no services found in %s", dir)
+	}
+	return render(pkgName, services)
+}
+
+// parseServices collects the services declared in the non-test files of dir
+func parseServices(dir, skip string) (string, []service, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	fset := token.NewFileSet()
+	var pkgName string
+	var services []service
+	var errs []error
+	for _, path := range files {
+		name := filepath.Base(path)
+		if name == skip || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return "", nil, err
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return "", nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		if pkgName == "" {
+			pkgName = file.Name.Name
+		}
+
+		neonName := importName(file)
+		if neonName == "" {
+			continue
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				svc, ok, err := parseService(fset, neonName, ts, st)
+				if err != nil {
+					errs = append(errs, err)
+				}
+				if ok {
+					services = append(services, svc)
+				}
+			}
+		}
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].typeName < services[j].typeName })
+	return pkgName, services, errors.Join(errs...)
+}
+
+// importName returns the name the file uses for the neon package, "" if not imported
+func importName(file *ast.File) string {
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if path != neonImportPath {
+			continue
+		}
+		if imp.Name != nil {
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				return ""
+			}
+			return imp.Name.Name
+		}
+		return "neon"
+	}
+	return ""
+}
+
+// neonType returns the name of a neon.X type expression, "" for any other type
+func neonType(neonName string, expr ast.Expr) string {
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != neonName {
+		return ""
+	}
+	return sel.Sel.Name
+}
+
+// parseService reads the module and endpoint tags of a struct, ok is false
+// when it does not embed neon.Module
+func parseService(fset *token.FileSet, neonName string, ts *ast.TypeSpec, st *ast.StructType) (svc service, ok bool, err error) {
+	svc.typeName = ts.Name.Name
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 && neonType(neonName, field.Type) == "Module" {
+			tag := fieldTag(field)
+			svc.base = tag.Get("base")
+			svc.version = tag.Get("v")
+			svc.host = tag.Get("host")
+			svc.middleware = tag.Get("middleware")
+			svc.mtls = tag.Get("mtls")
+			ok = true
+		}
+	}
+	if !ok {
+		return svc, false, nil
+	}
+	if ts.TypeParams != nil {
+		return svc, false, fmt.Errorf("%s: generic service %s is not supported", fset.Position(ts.Pos()), svc.typeName)
+	}
+
+	var errs []error
+	for _, field := range st.Fields.List {
+		method, isEndpoint := endpointMethods[neonType(neonName, field.Type)]
+		if !isEndpoint {
+			continue
+		}
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			handler := tag.Get("handler")
+			if handler == "" {
+				if ast.IsExported(name.Name) {
+					errs = append(errs, fmt.Errorf("%s: %s.%s: endpoint field must begin with a lowercase letter",
+						fset.Position(name.Pos()), svc.typeName, name.Name))
+					continue
+				}
+				handler = handlerName(name.Name)
+			} else if !token.IsIdentifier(handler) || !token.IsExported(handler) {
+				errs = append(errs, fmt.Errorf("%s: %s.%s: handler tag %q must name an exported method",
+					fset.Position(name.Pos()), svc.typeName, name.Name, handler))
+				continue
+			}
+			svc.endpoints = append(svc.endpoints, endpoint{
+				method:     method,
+				field:      name.Name,
+				handler:    handler,
+				url:        tag.Get("url"),
+				version:    tag.Get("v"),
+				host:       tag.Get("host"),
+				middleware: tag.Get("middleware"),
+				mtls:       tag.Get("mtls"),
+			})
+		}
+	}
+	return svc, true, errors.Join(errs...)
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	return reflect.StructTag(tag)
+}
+
+// handlerName : Name of the service method backing an endpoint field without a handler tag
+func handlerName(field string) string {
+	r := []rune(field)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// render writes the registration file and formats it
+func render(pkgName string, services []service) ([]byte, error) {
+	// Handlers are wrapped in http.HandlerFunc literals
+	usesHTTP := false
+	for _, svc := range services {
+		usesHTTP = usesHTTP || len(svc.endpoints) > 0
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by neon gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkgName)
+	if usesHTTP {
+		fmt.Fprintf(&b, "%q\n\n", "net/http")
+	}
+	fmt.Fprintf(&b, "%q\n)\n", neonImportPath)
+
+	for _, svc := range services {
+		fmt.Fprintf(&b, "\n// Register%[1]s adds s to app with static route and middleware wiring\n", svc.typeName)
+		fmt.Fprintf(&b, "func Register%[1]s(app neon.Registrar, s *%[1]s) error {\n", svc.typeName)
+		fmt.Fprintf(&b, "return app.AddServiceSpec(neon.ServiceSpec{\n")
+		fmt.Fprintf(&b, "Service: s,\nName: %q,\n", pkgName+"."+svc.typeName)
+		writeField(&b, "Base", svc.base)
+		writeField(&b, "Version", svc.version)
+		writeField(&b, "Host", svc.host)
+		writeField(&b, "Middleware", svc.middleware)
+		writeField(&b, "MTLS", svc.mtls)
+		fmt.Fprintf(&b, "Endpoints: []neon.EndpointSpec{\n")
+		for _, ep := range svc.endpoints {
+			// A method value would copy s for value receivers, hiding fields
+			// set after registration such as injected dependencies
+			fmt.Fprintf(&b, "{\nMethod: %s,\nField: %q,\nHandlerName: %q,\nHandler: func(w http.ResponseWriter, r *http.Request) { s.%s(w, r) },\n",
+				ep.method, ep.field, ep.handler, ep.handler)
+			writeField(&b, "URL", ep.url)
+			writeField(&b, "Version", ep.version)
+			writeField(&b, "Host", ep.host)
+			writeField(&b, "Middleware", ep.middleware)
+			writeField(&b, "MTLS", ep.mtls)
+			fmt.Fprintf(&b, "},\n")
+		}
+		fmt.Fprintf(&b, "},\n})\n}\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+// writeField writes a string field of a composite literal, omitting empty values
+func writeField(b *bytes.Buffer, name, value string) {
+	if value != "" {
+		fmt.Fprintf(b, "%s: %q,\n", name, value)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	for _, dir := range []string{"testdata/users", "testdata/missing"} {
+		got, err := generate(dir, defaultOutput)
+		if err != nil {
+			t.Fatalf("generate(%s) failed: %v", dir, err)
+		}
+		want, err := os.ReadFile(filepath.Join(dir, defaultOutput))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("generate(%s) differs from %s; rerun neon gen\n%s", dir, defaultOutput, got)
+		}
+	}
+}
+
+func TestGeneratedCodeCompiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds packages with the go command")
+	}
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+
+	if out, err := exec.Command(goCmd, "build", "./testdata/users").CombinedOutput(); err != nil {
+		t.Errorf("Expected generated code to compile: %v\n%s", err, out)
+	}
+
+	// A missing handler method is a compile error instead of a runtime log
+	out, err := exec.Command(goCmd, "build", "./testdata/missing").CombinedOutput()
+	if err == nil {
+		t.Fatal("Expected build to fail for a missing handler method")
+	}
+	if !strings.Contains(string(out), "s.Cancel undefined") {
+		t.Errorf("Expected error about missing Cancel method, got:\n%s", out)
+	}
+}
+
+func TestGenerateRejectsExportedField(t *testing.T) {
+	dir := t.TempDir()
+	src := `package bad
+
+import "github.com/sri-shubham/neon"
+
+type BadService struct {
+	neon.Module
+	List neon.Get ` + "`url:\"/\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := generate(dir, defaultOutput)
+	if err == nil || !strings.Contains(err.Error(), "BadService.List: endpoint field must begin with a lowercase letter") {
+		t.Errorf("Expected exported field error, got %v", err)
+	}
+}
+
+func TestGenerateRejectsBadHandlerTag(t *testing.T) {
+	dir := t.TempDir()
+	src := `package bad
+
+import "github.com/sri-shubham/neon"
+
+type BadService struct {
+	neon.Module
+	list neon.Get ` + "`url:\"/\" handler:\"list()\"`" + `
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "bad.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := generate(dir, defaultOutput)
+	if err == nil || !strings.Contains(err.Error(), `handler tag "list()" must name an exported method`) {
+		t.Errorf("Expected handler tag error, got %v", err)
+	}
+}
+
+func TestGenerateNoServices(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "plain.go"), []byte("package plain\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generate(dir, defaultOutput); err == nil {
+		t.Error("Expected error for a package without services")
+	}
+}
+
+func TestRunGen(t *testing.T) {
+	dir := t.TempDir()
+	src, err := os.ReadFile("testdata/users/users.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "users.go"), src, 0o644)
+
+	if err := run([]string{"gen", "-o", "routes_gen.go", dir}); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "routes_gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "func RegisterUserService(app neon.Registrar, s *UserService) error") {
+		t.Errorf("Expected RegisterUserService in output, got:\n%s", out)
+	}
+
+	// Running again skips the previous output instead of failing on it
+	if err := run([]string{"gen", "-o", "routes_gen.go", dir}); err != nil {
+		t.Errorf("Expected rerun to succeed, got %v", err)
+	}
+
+	if err := run([]string{"build"}); err == nil {
+		t.Error("Expected error for unknown command")
+	}
+}
@@ -0,0 +1,61 @@
+// Command neon is the command line tool of the Neon HTTP Framework.
+//
+// Usage:
+//
+//	neon gen [-o file] [dir]
+//
+// gen parses the services declared in the package in dir (default ".") and
+// writes RegisterXxx functions wiring their routes and middlewares without
+// reflection, see generate.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const defaultOutput = "neon_gen.go"
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "neon:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: neon gen [-o file] [dir]")
+	}
+	switch args[0] {
+	case "gen":
+		return runGen(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func runGen(args []string) error {
+	flags := flag.NewFlagSet("gen", flag.ContinueOnError)
+	output := flags.String("o", defaultOutput, "output file, relative to dir")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dir := "."
+	if flags.NArg() > 0 {
+		dir = flags.Arg(0)
+	}
+	out := *output
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	src, err := generate(dir, filepath.Base(out))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, src, 0o644)
+}
@@ -0,0 +1,15 @@
+package missing
+
+import (
+	"net/http"
+
+	"github.com/sri-shubham/neon"
+)
+
+type OrderService struct {
+	neon.Module `base:"/orders"`
+	list        neon.Get  `url:"/"`
+	cancel      neon.Post `url:"/{id}/cancel"`
+}
+
+func (s *OrderService) List(w http.ResponseWriter, r *http.Request) {}
@@ -0,0 +1,34 @@
+// Code generated by neon gen. DO NOT EDIT.
+
+package missing
+
+import (
+	"net/http"
+
+	"github.com/sri-shubham/neon"
+)
+
+// RegisterOrderService adds s to app with static route and middleware wiring
+func RegisterOrderService(app neon.Registrar, s *OrderService) error {
+	return app.AddServiceSpec(neon.ServiceSpec{
+		Service: s,
+		Name:    "missing.OrderService",
+		Base:    "/orders",
+		Endpoints: []neon.EndpointSpec{
+			{
+				Method:      http.MethodGet,
+				Field:       "list",
+				HandlerName: "List",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.List(w, r) },
+				URL:         "/",
+			},
+			{
+				Method:      http.MethodPost,
+				Field:       "cancel",
+				HandlerName: "Cancel",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.Cancel(w, r) },
+				URL:         "/{id}/cancel",
+			},
+		},
+	})
+}
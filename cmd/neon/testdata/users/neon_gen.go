@@ -0,0 +1,78 @@
+// Code generated by neon gen. DO NOT EDIT.
+
+package users
+
+import (
+	"net/http"
+
+	"github.com/sri-shubham/neon"
+)
+
+// RegisterHealthService adds s to app with static route and middleware wiring
+func RegisterHealthService(app neon.Registrar, s *HealthService) error {
+	return app.AddServiceSpec(neon.ServiceSpec{
+		Service: s,
+		Name:    "users.HealthService",
+		Endpoints: []neon.EndpointSpec{
+			{
+				Method:      neon.MethodAny,
+				Field:       "ping",
+				HandlerName: "Ping",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.Ping(w, r) },
+				URL:         "/ping",
+			},
+		},
+	})
+}
+
+// RegisterUserService adds s to app with static route and middleware wiring
+func RegisterUserService(app neon.Registrar, s *UserService) error {
+	return app.AddServiceSpec(neon.ServiceSpec{
+		Service:    s,
+		Name:       "users.UserService",
+		Base:       "/users",
+		Version:    "2",
+		Middleware: "Auth",
+		Endpoints: []neon.EndpointSpec{
+			{
+				Method:      http.MethodGet,
+				Field:       "list",
+				HandlerName: "List",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.List(w, r) },
+				URL:         "/",
+			},
+			{
+				Method:      http.MethodGet,
+				Field:       "get",
+				HandlerName: "Get",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.Get(w, r) },
+				URL:         "/{id:int}",
+				Middleware:  "Cache",
+			},
+			{
+				Method:      http.MethodPost,
+				Field:       "create",
+				HandlerName: "Create",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.Create(w, r) },
+				URL:         "/",
+				Middleware:  "RateLimit",
+			},
+			{
+				Method:      http.MethodDelete,
+				Field:       "remove",
+				HandlerName: "Remove",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.Remove(w, r) },
+				URL:         "/{id:int}",
+				Host:        "admin.example.com",
+				MTLS:        "required",
+			},
+			{
+				Method:      http.MethodGet,
+				Field:       "byName",
+				HandlerName: "Get",
+				Handler:     func(w http.ResponseWriter, r *http.Request) { s.Get(w, r) },
+				URL:         "/by-name/{name}",
+			},
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package users
+
+import (
+	"net/http"
+
+	web "github.com/sri-shubham/neon"
+)
+
+type UserService struct {
+	web.Module `base:"/users" v:"2" middleware:"Auth"`
+
+	list   web.Get    `url:"/"`
+	get    web.Get    `url:"/{id:int}" middleware:"Cache"`
+	create web.Post   `url:"/" middleware:"RateLimit"`
+	remove web.Delete `url:"/{id:int}" host:"admin.example.com" mtls:"required"`
+	byName web.Get    `url:"/by-name/{name}" handler:"Get"`
+
+	// Not endpoints
+	store map[string]string
+	Repo  *Repo `inject:""`
+}
+
+func (s *UserService) List(w http.ResponseWriter, r *http.Request) {}
+func (s *UserService) Get(w http.ResponseWriter, r *http.Request)  {}
+
+// Create has a value receiver; it must still see Repo once injected
+func (s UserService) Create(w http.ResponseWriter, r *http.Request) {
+	s.Repo.Save(r.FormValue("name"))
+}
+func (s *UserService) Remove(w http.ResponseWriter, r *http.Request) {}
+
+type HealthService struct {
+	web.Module
+	ping web.Any `url:"/ping"`
+}
+
+func (s *HealthService) Ping(w http.ResponseWriter, r *http.Request) {}
+
+type Repo struct {
+	names []string
+}
+
+func (r *Repo) Save(name string) { r.names = append(r.names, name) }
+
+// Not a service
+type Config struct {
+	Addr string
+}
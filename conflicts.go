@@ -0,0 +1,147 @@
+package neon
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// checkConflicts filters out endpoints whose routes duplicate or overlap a route
+// of an earlier endpoint, reporting each as a RegistrationError.
+// Patterns are validated against a scratch http.ServeMux so the rules match
+// exactly what the real mux would accept; the table's mux is left untouched.
+// Endpoints repeating a route are not in conflict when they differ in host or
+// typed wildcards: they are chained and tried in turn.
+func (t *routeTable) checkConflicts(eps []endpoint) ([]endpoint, RegistrationErrors) {
+	var errs RegistrationErrors
+	accepted := make([]endpoint, 0, len(eps))
+
+	routeOwners := make(map[string]int) // method + path shape (+ version) -> index into accepted
+	patternOwners := make(map[string]endpoint)
+	var patterns []string // registration order of patternOwners keys
+	scratch := http.NewServeMux()
+
+	for _, ep := range eps {
+		paths := t.config.versionedPaths(ep)
+		if head, ok := t.chainHead(ep, paths, routeOwners, accepted); ok {
+			accepted[head].chain = append(accepted[head].chain, ep)
+			continue
+		}
+
+		conflict := t.findConflict(ep, paths, routeOwners, accepted, patternOwners, patterns, scratch)
+		if conflict != nil {
+			errs = append(errs, conflict)
+			// The scratch mux may hold patterns of the rejected endpoint
+			scratch = http.NewServeMux()
+			for _, p := range patterns {
+				scratch.HandleFunc(p, http.NotFound)
+			}
+			continue
+		}
+
+		for _, path := range paths {
+			routeOwners[t.routeKey(ep, path)] = len(accepted)
+			if _, known := patternOwners[path]; !known {
+				patternOwners[path] = ep
+				patterns = append(patterns, path)
+			}
+		}
+		accepted = append(accepted, ep)
+	}
+	return accepted, errs
+}
+
+// chainHead returns the index of the accepted endpoint ep falls through from or to:
+// one owning all of ep's routes whose chain does not already hold an endpoint
+// with the same host and constraints
+func (t *routeTable) chainHead(ep endpoint, paths []string, routeOwners map[string]int, accepted []endpoint) (int, bool) {
+	head := -1
+	for _, path := range paths {
+		owner, dup := routeOwners[t.routeKey(ep, path)]
+		if !dup || (head >= 0 && owner != head) {
+			return 0, false
+		}
+		head = owner
+	}
+	if head < 0 {
+		return 0, false
+	}
+	condition := ep.condition()
+	for _, member := range append([]endpoint{accepted[head]}, accepted[head].chain...) {
+		if member.condition() == condition {
+			return 0, false
+		}
+	}
+	return head, true
+}
+
+// findConflict checks the paths of ep against routes accepted so far,
+// registering new patterns on scratch as it goes
+func (t *routeTable) findConflict(ep endpoint, paths []string, routeOwners map[string]int, accepted []endpoint, patternOwners map[string]endpoint, patterns []string, scratch *http.ServeMux) *RegistrationError {
+	for _, path := range paths {
+		if owner, dup := routeOwners[t.routeKey(ep, path)]; dup {
+			return conflictError(ep, fmt.Sprintf("duplicate route %s %s, already registered by %s", ep.method, path, accepted[owner].owner()))
+		}
+		if _, known := patternOwners[path]; known {
+			// Same pattern, different method: shares the path dispatcher
+			continue
+		}
+		if perr := tryHandle(scratch, path); perr != nil {
+			for _, p := range patterns {
+				if overlaps(p, path) {
+					return conflictError(ep, fmt.Sprintf("pattern %q overlaps %q registered by %s", path, p, patternOwners[p].owner()))
+				}
+			}
+			return conflictError(ep, fmt.Sprintf("invalid pattern %q: %v", path, perr))
+		}
+	}
+	return nil
+}
+
+// routeKey identifies a method and path shape; negotiated versions may share a path
+func (t *routeTable) routeKey(ep endpoint, path string) string {
+	key := ep.method + " " + patternShape(path)
+	if t.config.negotiatesVersion() && ep.mounted == nil {
+		key += " v" + ep.version
+	}
+	return key
+}
+
+// tryHandle registers pattern on mux, converting a registration panic into an error
+// A nil handler registers http.NotFound, for checking patterns on a scratch mux
+func tryHandle(mux *http.ServeMux, pattern string, handler ...http.HandlerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	h := http.HandlerFunc(http.NotFound)
+	if len(handler) > 0 {
+		h = handler[0]
+	}
+	mux.HandleFunc(pattern, h)
+	return nil
+}
+
+// overlaps reports whether http.ServeMux refuses to hold both patterns
+func overlaps(a, b string) bool {
+	mux := http.NewServeMux()
+	if tryHandle(mux, a) != nil {
+		return false
+	}
+	return tryHandle(mux, b) != nil
+}
+
+func conflictError(ep endpoint, reason string) *RegistrationError {
+	return &RegistrationError{Service: ep.service, Field: ep.field, Reason: reason}
+}
+
+// owner names the service field that declared the endpoint
+func (ep endpoint) owner() string {
+	if ep.field == "" {
+		return ep.service
+	}
+	if ep.service == "" {
+		return ep.field
+	}
+	return ep.service + "." + ep.field
+}
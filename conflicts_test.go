@@ -0,0 +1,104 @@
+package neon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type OverlapAService struct {
+	Module `base:"/a"`
+	getX   Get `url:"/{x}"`
+}
+
+func (s OverlapAService) GetX(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("a"))
+}
+
+type OverlapBService struct {
+	Module `base:"/"`
+	getB   Get `url:"/{y}/b"`
+}
+
+func (s OverlapBService) GetB(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("b"))
+}
+
+type RenamedWildcardService struct {
+	Module     `base:"/users"`
+	updateUser Put `url:"/{name}"`
+}
+
+func (s RenamedWildcardService) UpdateUser(w http.ResponseWriter, r *http.Request) {}
+
+func conflictErrors(t *testing.T, err error) RegistrationErrors {
+	t.Helper()
+	var errs RegistrationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected RegistrationErrors, got %v", err)
+	}
+	return errs
+}
+
+func TestDuplicateRouteConflict(t *testing.T) {
+	app := New()
+	app.AddService(&UserServiceV1{})
+	app.AddService(&UserServiceV2{}) // same path without versioning
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 {
+		t.Fatalf("Expected 1 conflict, got %v", errs)
+	}
+	if errs[0].Service != "neon.UserServiceV2" || errs[0].Field != "getUser" {
+		t.Errorf("Expected conflict on neon.UserServiceV2.getUser, got %v", errs[0])
+	}
+	if !strings.Contains(errs[0].Reason, "neon.UserServiceV1.getUser") {
+		t.Errorf("Expected reason to name the earlier owner, got %q", errs[0].Reason)
+	}
+
+	// First registration wins
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users/x", nil))
+	if w.Body.String() != "v1 x" {
+		t.Errorf("Expected 'v1 x', got '%s'", w.Body.String())
+	}
+}
+
+func TestOverlappingPatternConflict(t *testing.T) {
+	app := New()
+	app.AddService(&OverlapAService{})
+	app.AddService(&OverlapBService{})
+
+	// Must report instead of panicking inside http.ServeMux
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 || errs[0].Service != "neon.OverlapBService" {
+		t.Fatalf("Expected overlap reported for neon.OverlapBService, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Reason, "overlaps") || !strings.Contains(errs[0].Reason, "neon.OverlapAService.getX") {
+		t.Errorf("Unexpected reason %q", errs[0].Reason)
+	}
+}
+
+func TestRenamedWildcardConflict(t *testing.T) {
+	app := New()
+	app.AddService(&UserServiceV1{})
+	app.AddService(&RenamedWildcardService{})
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 || errs[0].Service != "neon.RenamedWildcardService" {
+		t.Fatalf("Expected conflict for neon.RenamedWildcardService, got %v", errs)
+	}
+}
+
+func TestNegotiatedVersionsDoNotConflict(t *testing.T) {
+	app := New()
+	app.Versioning = HeaderVersioning
+	app.AddService(&UserServiceV1{})
+	app.AddService(&UserServiceV2{})
+
+	if err := app.Build(); err != nil {
+		t.Errorf("Expected no conflicts, got %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+package neon
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Constraint : Reports whether a path value is acceptable for a typed wildcard
+type Constraint func(value string) bool
+
+// ConstraintFactory : Builds a Constraint from the argument written in the url tag,
+// e.g. "^[A-Z]{3}$" for {code:regex(^[A-Z]{3}$)}; arg is empty when none is given
+type ConstraintFactory func(arg string) (Constraint, error)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// builtinConstraints are available to every app without registration
+var builtinConstraints = map[string]ConstraintFactory{
+	"int": func(string) (Constraint, error) {
+		return func(v string) bool {
+			_, err := strconv.ParseInt(v, 10, 64)
+			return err == nil
+		}, nil
+	},
+	"alpha": func(string) (Constraint, error) {
+		return func(v string) bool {
+			return v != "" && strings.IndexFunc(v, func(r rune) bool { return !unicode.IsLetter(r) }) < 0
+		}, nil
+	},
+	"alnum": func(string) (Constraint, error) {
+		return func(v string) bool {
+			return v != "" && strings.IndexFunc(v, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) < 0
+		}, nil
+	},
+	"uuid": func(string) (Constraint, error) {
+		return uuidPattern.MatchString, nil
+	},
+	"regex": func(arg string) (Constraint, error) {
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	},
+}
+
+// RegisterConstraint makes a custom constraint type usable in url tags as {name:type}
+// or {name:type(arg)}. It replaces a built-in constraint of the same name.
+// Routes already built keep the constraint they were built with.
+func (s *App) RegisterConstraint(name string, factory ConstraintFactory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.constraints[name] = factory
+}
+
+// paramConstraint : A constraint bound to the wildcard at a position in the pattern
+type paramConstraint struct {
+	index int
+	name  string
+	spec  string // as written in the tag, e.g. "regex(^[a-z]+$)"
+	check Constraint
+}
+
+// parsePattern strips constraints from typed wildcards, returning the
+// http.ServeMux pattern and the constraints to check on each request
+func (s *App) parsePattern(url string) (string, []paramConstraint, error) {
+	var b strings.Builder
+	var constraints []paramConstraint
+	index := 0
+	for i := 0; i < len(url); {
+		if url[i] != '{' {
+			b.WriteByte(url[i])
+			i++
+			continue
+		}
+
+		// Find the matching brace; regex arguments may contain braces themselves
+		depth, j := 0, i
+		for ; j < len(url); j++ {
+			if url[j] == '{' {
+				depth++
+			} else if url[j] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if j == len(url) {
+			return "", nil, fmt.Errorf("unbalanced braces in %q", url)
+		}
+		inner := url[i+1 : j]
+		i = j + 1
+
+		if inner == "$" {
+			b.WriteString("{$}")
+			continue
+		}
+
+		name, spec, typed := strings.Cut(inner, ":")
+		multi := ""
+		if typed && strings.HasSuffix(spec, "...") {
+			spec, multi = strings.TrimSuffix(spec, "..."), "..."
+		}
+		b.WriteString("{" + name + multi + "}")
+
+		if typed {
+			check, err := s.buildConstraint(spec)
+			if err != nil {
+				return "", nil, fmt.Errorf("wildcard {%s}: %w", inner, err)
+			}
+			constraints = append(constraints, paramConstraint{index: index, name: name, spec: spec, check: check})
+		}
+		index++
+	}
+	return b.String(), constraints, nil
+}
+
+// buildConstraint resolves a spec such as "int" or "regex(^a+$)"
+func (s *App) buildConstraint(spec string) (Constraint, error) {
+	kind, arg := spec, ""
+	if open := strings.IndexByte(spec, '('); open >= 0 {
+		if !strings.HasSuffix(spec, ")") {
+			return nil, fmt.Errorf("missing closing parenthesis in constraint %q", spec)
+		}
+		kind, arg = spec[:open], spec[open+1:len(spec)-1]
+	}
+	factory, ok := s.constraints[kind]
+	if !ok {
+		factory, ok = builtinConstraints[kind]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown constraint %q", kind)
+	}
+	return factory(arg)
+}
+
+// wildcardNames lists the wildcard names of a mux pattern in order, skipping {$}
+func wildcardNames(pattern string) []string {
+	var names []string
+	for {
+		open := strings.IndexByte(pattern, '{')
+		if open < 0 {
+			return names
+		}
+		end := strings.IndexByte(pattern[open:], '}')
+		if end < 0 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[open+1:open+end], "...")
+		if name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[open+end+1:]
+	}
+}
+
+// patternShape erases wildcard names so patterns matching the same paths compare equal
+func patternShape(pattern string) string {
+	names := wildcardNames(pattern)
+	for _, name := range names {
+		pattern = strings.Replace(pattern, "{"+name+"}", "{}", 1)
+		pattern = strings.Replace(pattern, "{"+name+"...}", "{...}", 1)
+	}
+	return pattern
+}
+
+// chainHandler serves the first endpoint of chain whose host and constraints accept
+// the request, trying the unconditional endpoint, if any, last. Chained endpoints
+// share one mux pattern, named after the first endpoint, so path values are
+// copied to each endpoint's own wildcard names.
+func (t *routeTable) chainHandler(chain []endpoint) http.HandlerFunc {
+	ordered := make([]endpoint, 0, len(chain))
+	var fallback []endpoint
+	for _, ep := range chain {
+		if ep.conditional() {
+			ordered = append(ordered, ep)
+		} else {
+			fallback = append(fallback, ep)
+		}
+	}
+	ordered = append(ordered, fallback...)
+
+	canonical := wildcardNames(chain[0].url)
+	return func(w http.ResponseWriter, r *http.Request) {
+		values := make([]string, len(canonical))
+		for i, name := range canonical {
+			values[i] = r.PathValue(name)
+		}
+
+	candidates:
+		for _, ep := range ordered {
+			var hostNames, hostValues []string
+			if ep.host != nil {
+				var ok bool
+				if hostNames, hostValues, ok = ep.host.match(r.Host); !ok {
+					continue
+				}
+			}
+			for _, c := range ep.constraints {
+				if c.index >= len(values) || !c.check(values[c.index]) {
+					continue candidates
+				}
+			}
+			for i, name := range wildcardNames(ep.url) {
+				if i < len(values) && name != canonical[i] {
+					r.SetPathValue(name, values[i])
+				}
+			}
+			for i, name := range hostNames {
+				r.SetPathValue(name, hostValues[i])
+			}
+			ep.handler(w, r)
+			return
+		}
+		t.notFound(w, r)
+	}
+}
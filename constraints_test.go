@@ -0,0 +1,213 @@
+package neon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type ArticleService struct {
+	Module        `base:"/articles"`
+	getByID       Get    `url:"/{id:int}"`
+	getBySlug     Get    `url:"/{slug:alpha}"`
+	getByUUID     Get    `url:"/uuid/{uuid:uuid}"`
+	getByCode     Get    `url:"/code/{code:regex(^[A-Z]{3}$)}"`
+	getCatchAll   Get    `url:"/files/{path...}"`
+	deleteArticle Delete `url:"/{id:int}"`
+}
+
+func (s ArticleService) GetByID(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("id " + r.PathValue("id")))
+}
+
+func (s ArticleService) GetBySlug(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("slug " + r.PathValue("slug")))
+}
+
+func (s ArticleService) GetByUUID(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("uuid " + r.PathValue("uuid")))
+}
+
+func (s ArticleService) GetByCode(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("code " + r.PathValue("code")))
+}
+
+func (s ArticleService) GetCatchAll(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("file " + r.PathValue("path")))
+}
+
+func (s ArticleService) DeleteArticle(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("deleted " + r.PathValue("id")))
+}
+
+func TestRouteConstraints(t *testing.T) {
+	app := New()
+	app.AddService(&ArticleService{})
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		expected string
+	}{
+		{"GET", "/articles/42", http.StatusOK, "id 42"},
+		{"GET", "/articles/hello", http.StatusOK, "slug hello"}, // falls through from {id:int}
+		{"GET", "/articles/hello-42", http.StatusNotFound, ""},
+		{"GET", "/articles/uuid/123e4567-e89b-12d3-a456-426614174000", http.StatusOK, "uuid 123e4567-e89b-12d3-a456-426614174000"},
+		{"GET", "/articles/uuid/not-a-uuid", http.StatusNotFound, ""},
+		{"GET", "/articles/code/ABC", http.StatusOK, "code ABC"},
+		{"GET", "/articles/code/ABCD", http.StatusNotFound, ""},
+		{"GET", "/articles/files/a/b.txt", http.StatusOK, "file a/b.txt"},
+		{"DELETE", "/articles/7", http.StatusOK, "deleted 7"},
+		{"DELETE", "/articles/seven", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := serve(app, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != test.code {
+			t.Errorf("%s %s: expected status %d, got %d", test.method, test.path, test.code, w.Code)
+			continue
+		}
+		if test.code == http.StatusOK && w.Body.String() != test.expected {
+			t.Errorf("%s %s: expected '%s', got '%s'", test.method, test.path, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestRouteConstraintsInRoutes(t *testing.T) {
+	app := New()
+	app.AddService(&ArticleService{})
+
+	found := false
+	for _, rt := range app.Routes() {
+		if rt.Handler == "GetByCode" {
+			found = true
+			if rt.Path != "/articles/code/{code}" {
+				t.Errorf("Expected mux pattern path, got %s", rt.Path)
+			}
+			if !reflect.DeepEqual(rt.Constraints, map[string]string{"code": "regex(^[A-Z]{3}$)"}) {
+				t.Errorf("Unexpected constraints %v", rt.Constraints)
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected GetByCode route to be listed")
+	}
+}
+
+type EvenService struct {
+	Module  `base:"/even"`
+	getEven Get `url:"/{n:even}"`
+}
+
+func (s EvenService) GetEven(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("even"))
+}
+
+func TestCustomConstraint(t *testing.T) {
+	app := New()
+	app.RegisterConstraint("even", func(string) (Constraint, error) {
+		return func(v string) bool {
+			return v != "" && strings.ContainsAny(v[len(v)-1:], "02468")
+		}, nil
+	})
+	app.AddService(&EvenService{})
+
+	if w := serve(app, httptest.NewRequest("GET", "/even/4", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/even/3", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
+
+// Run with -race: constraints may be registered while the table is rebuilt
+func TestRegisterConstraintWhileUpdating(t *testing.T) {
+	app := New()
+	app.Build()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		app.AddService(&TestService{})
+	}()
+	app.RegisterConstraint("even", func(string) (Constraint, error) {
+		return func(v string) bool { return strings.ContainsAny(v[len(v)-1:], "02468") }, nil
+	})
+	<-done
+
+	if err := app.AddService(&EvenService{}); err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/even/4", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+}
+
+func TestUnknownConstraint(t *testing.T) {
+	app := New()
+	app.AddService(&EvenService{}) // "even" is not registered
+
+	var errs RegistrationErrors
+	if !errors.As(app.Build(), &errs) || len(errs) != 1 {
+		t.Fatalf("Expected one registration error, got %v", errs)
+	}
+	if errs[0].Tag != `url:"/{n:even}"` || !strings.Contains(errs[0].Reason, `unknown constraint "even"`) {
+		t.Errorf("Unexpected error %v", errs[0])
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	app := New()
+
+	tests := []struct {
+		in      string
+		pattern string
+		names   []string
+	}{
+		{"/users/{id}", "/users/{id}", nil},
+		{"/users/{id:int}/posts/{slug:alpha}", "/users/{id}/posts/{slug}", []string{"id", "slug"}},
+		{"/c/{code:regex(^[A-Z]{3}$)}", "/c/{code}", []string{"code"}},
+		{"/f/{path:regex(.+)...}", "/f/{path...}", []string{"path"}},
+		{"/exact/{$}", "/exact/{$}", nil},
+	}
+
+	for _, test := range tests {
+		pattern, constraints, err := app.parsePattern(test.in)
+		if err != nil {
+			t.Errorf("parsePattern(%q): unexpected error %v", test.in, err)
+			continue
+		}
+		if pattern != test.pattern {
+			t.Errorf("parsePattern(%q): expected %q, got %q", test.in, test.pattern, pattern)
+		}
+		var names []string
+		for _, c := range constraints {
+			names = append(names, c.name)
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("parsePattern(%q): expected constraints on %v, got %v", test.in, test.names, names)
+		}
+	}
+
+	for _, bad := range []string{"/a/{id:int", "/a/{id:regex([)}", "/a/{id:regex(x}"} {
+		if _, _, err := app.parsePattern(bad); err == nil {
+			t.Errorf("parsePattern(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPatternShape(t *testing.T) {
+	if patternShape("/a/{id}/b/{rest...}") != patternShape("/a/{slug}/b/{path...}") {
+		t.Error("Expected patterns differing only in wildcard names to share a shape")
+	}
+	if patternShape("/a/{id}") == patternShape("/a/{id}/b") {
+		t.Error("Expected different patterns to have different shapes")
+	}
+}
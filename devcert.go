@@ -0,0 +1,183 @@
+package neon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const devCertValidity = 30 * 24 * time.Hour
+
+// Files written by DevCert.WriteFiles
+const (
+	DevCAFile   = "neon-dev-ca.pem"
+	DevCertFile = "neon-dev-cert.pem"
+	DevKeyFile  = "neon-dev-key.pem"
+)
+
+// DevCert : A generated development CA and a leaf certificate it signed
+// Trust CAPEM in curl or a browser to accept the leaf
+type DevCert struct {
+	CA    *x509.Certificate
+	CAPEM []byte
+
+	// Certificate is the leaf with its private key, ready for tls.Config
+	Certificate tls.Certificate
+	CertPEM     []byte
+	KeyPEM      []byte
+}
+
+// GenerateDevCert creates an in-memory CA and a leaf certificate for hosts,
+// localhost, 127.0.0.1 and ::1 when none are given. Both are valid for 30 days.
+// They are meant for local development only.
+func GenerateDevCert(hosts ...string) (*DevCert, error) {
+	if len(hosts) == 0 {
+		hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("neon: generate dev CA key: %w", err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{Organization: []string{"Neon Development"}, CommonName: "Neon Development CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(devCertValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("neon: generate dev CA: %w", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("neon: generate dev certificate key: %w", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{Organization: []string{"Neon Development"}, CommonName: hosts[0]},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(devCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("neon: generate dev certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := &DevCert{
+		CA:      ca,
+		CAPEM:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		CertPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	cert.Certificate, err = tls.X509KeyPair(cert.CertPEM, cert.KeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+func randomSerial() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	return serial
+}
+
+// WriteFiles writes the CA, certificate and key to dir as DevCAFile,
+// DevCertFile and DevKeyFile; the key is only readable by the owner
+func (c *DevCert) WriteFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+		perm os.FileMode
+	}{
+		{DevCAFile, c.CAPEM, 0o644},
+		{DevCertFile, c.CertPEM, 0o644},
+		{DevKeyFile, c.KeyPEM, 0o600},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), f.data, f.perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errDevTLSEnv = errors.New("DevTLS is only available in DevEnv")
+
+// prepareDevTLS generates the development certificate served by the default
+// listener when DevTLS is set and no certificate is configured
+func (s *App) prepareDevTLS() error {
+	if !s.DevTLS {
+		return nil
+	}
+	if s.Env != DevEnv {
+		return fmt.Errorf("neon: %w", errDevTLSEnv)
+	}
+	if s.TLSCert != "" || s.TLSKey != "" || hasCertificates(s.TLSConfig) {
+		// Configured certificates win
+		return nil
+	}
+
+	cert, err := GenerateDevCert()
+	if err != nil {
+		return err
+	}
+	if s.DevCertDir != "" {
+		if err := cert.WriteFiles(s.DevCertDir); err != nil {
+			return fmt.Errorf("neon: write dev certificate: %w", err)
+		}
+		s.Logger.Info("Development certificate written", "ca", filepath.Join(s.DevCertDir, DevCAFile))
+	}
+
+	s.mu.Lock()
+	s.devCert = cert
+	s.mu.Unlock()
+	return nil
+}
+
+// devTLSConfig returns TLSConfig serving the generated development certificate
+func (s *App) devTLSConfig() *tls.Config {
+	cfg := &tls.Config{}
+	if s.TLSConfig != nil {
+		cfg = s.TLSConfig.Clone()
+	}
+	cfg.Certificates = []tls.Certificate{s.devCert.Certificate}
+	return cfg
+}
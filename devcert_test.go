@@ -0,0 +1,117 @@
+package neon
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestGenerateDevCert(t *testing.T) {
+	cert, err := GenerateDevCert()
+	if err != nil {
+		t.Fatalf("GenerateDevCert failed: %v", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(cert.CAPEM) {
+		t.Fatal("Expected CAPEM to hold the CA certificate")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
+		if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots}); err != nil {
+			t.Errorf("Expected certificate to be valid for %s: %v", host, err)
+		}
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err == nil {
+		t.Error("Expected certificate to be invalid for example.com")
+	}
+
+	custom, err := GenerateDevCert("app.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, _ = x509.ParseCertificate(custom.Certificate.Certificate[0])
+	if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "app.test" {
+		t.Errorf("Expected certificate for app.test, got %v", leaf.DNSNames)
+	}
+}
+
+func TestDevTLS(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	app := New(&Config{Port: freePort(t), DevTLS: true, DevCertDir: dir})
+	app.SetEnv(DevEnv)
+	app.AddService(&TestService{})
+	runApp(t, app)
+	url := "https://localhost:" + strconv.Itoa(app.Port) + "/test/endpoint"
+	getBody(t, insecureClient, url) // wait for the server
+
+	// The written CA is all a client needs to trust the server
+	caPEM, err := os.ReadFile(filepath.Join(dir, DevCAFile))
+	if err != nil {
+		t.Fatalf("Expected CA file to be written: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	resp, body := getBody(t, client, url)
+	if resp.TLS == nil || body != "test response" {
+		t.Errorf("Expected 'test response' over verified TLS, got '%s'", body)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, DevKeyFile))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected key file readable by owner only, got %v %v", info.Mode(), err)
+	}
+	if _, err := tls.LoadX509KeyPair(filepath.Join(dir, DevCertFile), filepath.Join(dir, DevKeyFile)); err != nil {
+		t.Errorf("Expected written certificate and key to form a pair: %v", err)
+	}
+}
+
+func TestDevTLSOnlyInDevEnv(t *testing.T) {
+	var log []string
+	app := New(&Config{Port: freePort(t), DevTLS: true})
+	app.SetEnv(ProdEnv)
+	app.AddService(&LifecycleService{name: "a", log: &log})
+
+	if err := app.RunContext(context.Background()); !errors.Is(err, errDevTLSEnv) {
+		t.Fatalf("Expected DevTLS to be refused outside DevEnv, got %v", err)
+	}
+	if len(log) != 0 {
+		t.Errorf("Expected no service to be initialized, got %v", log)
+	}
+}
+
+func TestDevTLSWithListeners(t *testing.T) {
+	dir := t.TempDir()
+	app := New(&Config{DevTLS: true, DevCertDir: dir, Listeners: []Listener{{Addr: "127.0.0.1:" + strconv.Itoa(freePort(t))}}})
+	app.SetEnv(DevEnv)
+	app.AddService(&TestService{})
+
+	if err := app.RunContext(context.Background()); !errors.Is(err, errDevTLSListeners) {
+		t.Fatalf("Expected DevTLS with Listeners to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DevCAFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no certificate to be written, got %v", err)
+	}
+}
+
+func TestDevTLSConfiguredCertificateWins(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir())
+	app := New(&Config{DevTLS: true, TLSCert: certFile, TLSKey: keyFile})
+	app.SetEnv(DevEnv)
+	if err := app.prepareDevTLS(); err != nil {
+		t.Fatal(err)
+	}
+	if app.devCert != nil {
+		t.Error("Expected no development certificate when certificates are configured")
+	}
+}
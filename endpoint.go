@@ -1,6 +1,8 @@
 package neon
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
@@ -10,28 +12,87 @@ import (
 type endpoint struct {
 	method  string
 	url     string
+	version string
 	handler func(w http.ResponseWriter, r *http.Request)
+
+	// mounted is set for handlers added with App.Mount; they match every
+	// method under their prefix and are never versioned
+	mounted http.Handler
+
+	// host and constraints of typed wildcards are checked before handler runs
+	host        *hostPattern
+	constraints []paramConstraint
+	// mtls requires a verified client certificate
+	mtls bool
+	// chain holds endpoints with the same route, tried in order when
+	// host or constraints reject the request
+	chain []endpoint
+
+	// Metadata reported by App.Routes
+	service             string
+	field               string
+	handlerName         string
+	globalMiddlewares   []string
+	groupMiddlewares    []string
+	moduleMiddlewares   []string
+	endpointMiddlewares []string
+}
+
+// conditional reports whether the endpoint may reject requests matching its pattern
+func (ep endpoint) conditional() bool {
+	return ep.host != nil || len(ep.constraints) > 0
+}
+
+// condition identifies the host and constraints of an endpoint, "" when unconditional
+func (ep endpoint) condition() string {
+	if !ep.conditional() {
+		return ""
+	}
+	var b strings.Builder
+	if ep.host != nil {
+		b.WriteString(ep.host.shape())
+	}
+	for _, c := range ep.constraints {
+		fmt.Fprintf(&b, " %d:%s", c.index, c.spec)
+	}
+	return b.String()
+}
+
+// handlerMethodName : Name of the service method backing an endpoint field
+// The handler tag names it explicitly; otherwise it is the field name with an
+// upper case first letter
+func handlerMethodName(ft reflect.StructField) string {
+	if name := ft.Tag.Get("handler"); name != "" {
+		return name
+	}
+	return strings.ToUpper(string(ft.Name[0])) + ft.Name[1:]
 }
 
 // checkAPIMethodExists : Checks if api field formt is correct and methods exist
-// Field name should beign with Lower Caps; corresponding handler should have same name
-// but begin with Upper caps
-func checkAPIMethodExists(sv reflect.Value, st reflect.Type, ft reflect.StructField) (*func(w http.ResponseWriter, r *http.Request), bool) {
-	if string(ft.Name[0]) == strings.ToUpper(string(ft.Name[0])) {
-		return nil, false
+// Without a handler tag the field name should beign with Lower Caps; corresponding
+// handler should have same name but begin with Upper caps
+// The handler must have the signature of an http.HandlerFunc
+// Methods are looked up on the registered pointer, so both value and pointer
+// receivers are found and bound to the registered instance
+func checkAPIMethodExists(sv reflect.Value, ft reflect.StructField) (*func(w http.ResponseWriter, r *http.Request), error) {
+	if ft.Tag.Get("handler") == "" && string(ft.Name[0]) == strings.ToUpper(string(ft.Name[0])) {
+		return nil, errors.New("endpoint field must begin with a lowercase letter")
 	}
-	handlerName := strings.ToUpper(string(ft.Name[0])) + ft.Name[1:]
-	_, ok := st.MethodByName(handlerName)
+	handlerName := handlerMethodName(ft)
+	_, ok := sv.Type().MethodByName(handlerName)
 	if !ok {
-		return nil, false
+		return nil, fmt.Errorf("handler method %s not found", handlerName)
 	}
 
 	handlerMethod := sv.MethodByName(handlerName)
-
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		rValue := reflect.ValueOf(r)
-		wValue := reflect.ValueOf(w)
-		handlerMethod.Call([]reflect.Value{wValue, rValue})
+	if mt := handlerMethod.Type(); mt != handlerFuncType {
+		return nil, fmt.Errorf("handler method %s has signature %s, want %s", handlerName, mt, handlerFuncType)
 	}
-	return &handler, true
+
+	// Bound once here; each call still goes through reflect's method value,
+	// which allocates. Services registered from `neon gen` specs do not.
+	handler := handlerMethod.Interface().(func(http.ResponseWriter, *http.Request))
+	return &handler, nil
 }
+
+var handlerFuncType = reflect.TypeOf((func(http.ResponseWriter, *http.Request))(nil))
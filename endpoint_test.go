@@ -1,9 +1,11 @@
 package neon
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
 	"testing"
 )
 
@@ -15,10 +17,10 @@ func TestCheckAPIMethodExists(t *testing.T) {
 	// Get the field info for getTest
 	field, _ := serviceType.FieldByName("getTest")
 
-	handler, exists := checkAPIMethodExists(serviceValue, serviceType, field)
+	handler, err := checkAPIMethodExists(serviceValue, field)
 
-	if !exists {
-		t.Fatal("Expected handler to exist for GetTest method")
+	if err != nil {
+		t.Fatalf("Expected handler to exist for GetTest method, got %v", err)
 	}
 
 	if handler == nil {
@@ -44,10 +46,10 @@ func TestCheckAPIMethodExists_NonExistent(t *testing.T) {
 	// Get the field info for nonExistent (should not have a corresponding handler)
 	field, _ := serviceType.FieldByName("nonExistent")
 
-	handler, exists := checkAPIMethodExists(serviceValue, serviceType, field)
+	handler, err := checkAPIMethodExists(serviceValue, field)
 
-	if exists {
-		t.Error("Expected handler to not exist for non-existent method")
+	if err == nil || err.Error() != "handler method NonExistent not found" {
+		t.Errorf("Expected handler not found error, got %v", err)
 	}
 
 	if handler != nil {
@@ -63,9 +65,9 @@ func TestCheckAPIMethodExists_LowercaseField(t *testing.T) {
 	// Get the field info for uppercaseField (should fail because field starts with uppercase)
 	field, _ := serviceType.FieldByName("UppercaseField")
 
-	handler, exists := checkAPIMethodExists(serviceValue, serviceType, field)
+	handler, err := checkAPIMethodExists(serviceValue, field)
 
-	if exists {
+	if err == nil {
 		t.Error("Expected handler to not exist for uppercase field")
 	}
 
@@ -87,3 +89,276 @@ func (s TestEndpointService) GetTest(w http.ResponseWriter, r *http.Request) {
 }
 
 // Note: No handler for nonExistent, and no handler for UppercaseField
+
+type HandlerTagService struct {
+	Module    `base:"/tagged"`
+	iDLookup  Get  `url:"/id/{id}" handler:"LookupByID"`
+	nameFind  Get  `url:"/name/{name}" handler:"LookupByID"`
+	Exported  Get  `url:"/exported" handler:"LookupByID"`
+	badSig    Post `url:"/bad" handler:"BadSignature"`
+	missing   Post `url:"/missing" handler:"Missing"`
+	unchanged Get  `url:"/unchanged"`
+}
+
+func (s HandlerTagService) LookupByID(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("lookup " + r.PathValue("id") + r.PathValue("name")))
+}
+
+func (s HandlerTagService) BadSignature(w http.ResponseWriter) {}
+
+func (s HandlerTagService) Unchanged(w http.ResponseWriter, r *http.Request) {}
+
+func TestHandlerTag(t *testing.T) {
+	service := &HandlerTagService{}
+	serviceValue := reflect.ValueOf(service)
+	serviceType := reflect.TypeOf(service).Elem()
+
+	for _, name := range []string{"iDLookup", "nameFind", "Exported", "unchanged"} {
+		field, _ := serviceType.FieldByName(name)
+		if _, err := checkAPIMethodExists(serviceValue, field); err != nil {
+			t.Errorf("Expected handler for %s, got %v", name, err)
+		}
+	}
+
+	field, _ := serviceType.FieldByName("badSig")
+	_, err := checkAPIMethodExists(serviceValue, field)
+	want := "handler method BadSignature has signature func(http.ResponseWriter), want func(http.ResponseWriter, *http.Request)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Expected %q, got %v", want, err)
+	}
+
+	field, _ = serviceType.FieldByName("missing")
+	if _, err := checkAPIMethodExists(serviceValue, field); err == nil || err.Error() != "handler method Missing not found" {
+		t.Errorf("Expected handler not found error, got %v", err)
+	}
+}
+
+func TestHandlerTagRoutes(t *testing.T) {
+	app := New()
+	err := app.AddService(&HandlerTagService{})
+	if err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+
+	var errs RegistrationErrors
+	if !errors.As(app.Build(), &errs) || len(errs) != 2 {
+		t.Fatalf("Expected errors for badSig and missing, got %v", errs)
+	}
+	if errs[0].Field != "badSig" || errs[1].Field != "missing" {
+		t.Errorf("Expected errors for badSig and missing, got %v", errs)
+	}
+
+	// Several fields share one handler
+	for path, body := range map[string]string{"/tagged/id/7": "lookup 7", "/tagged/name/bob": "lookup bob", "/tagged/exported": "lookup "} {
+		w := serve(app, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || w.Body.String() != body {
+			t.Errorf("%s: expected 200 %q, got %d %q", path, body, w.Code, w.Body.String())
+		}
+	}
+
+	handlers := map[string]string{}
+	for _, rt := range app.Routes() {
+		handlers[rt.Path] = rt.Handler
+	}
+	if handlers["/tagged/id/{id}"] != "LookupByID" || handlers["/tagged/unchanged"] != "Unchanged" {
+		t.Errorf("Expected handler names from tags in Routes, got %v", handlers)
+	}
+}
+
+type CounterService struct {
+	Module `base:"/counter"`
+	hits   int
+	bump   Post `url:"/"`
+	read   Get  `url:"/"`
+}
+
+func (s *CounterService) Bump(w http.ResponseWriter, r *http.Request) {
+	s.hits++
+}
+
+func (s CounterService) Read(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(strconv.Itoa(s.hits)))
+}
+
+func TestPointerReceiverHandlers(t *testing.T) {
+	service := &CounterService{}
+	app := New()
+	if err := app.AddService(service); err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		serve(app, httptest.NewRequest("POST", "/counter/", nil))
+	}
+	// Handlers are bound to the registered instance, not a copy
+	if service.hits != 3 {
+		t.Errorf("Expected 3 hits on the registered service, got %d", service.hits)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/counter/", nil)); w.Body.String() != "3" {
+		t.Errorf("Expected value receiver to see 3 hits, got '%s'", w.Body.String())
+	}
+}
+
+type GreetingService struct {
+	Module   `base:"/greet"`
+	greeting string
+	hello    Get `url:"/hello"`
+}
+
+func (s GreetingService) Hello(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(s.greeting))
+}
+
+func TestHandlerBindsReceiver(t *testing.T) {
+	service := &GreetingService{greeting: "hi"}
+	field, _ := reflect.TypeOf(service).Elem().FieldByName("hello")
+	handler, err := checkAPIMethodExists(reflect.ValueOf(service), field)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes to the service after registration are visible to the handler
+	service.greeting = "hello"
+	w := httptest.NewRecorder()
+	(*handler)(w, httptest.NewRequest("GET", "/greet/hello", nil))
+	if w.Body.String() != "hello" {
+		t.Errorf("Expected 'hello', got '%s'", w.Body.String())
+	}
+}
+
+// discardWriter is a ResponseWriter that allocates nothing
+type discardWriter struct{ header http.Header }
+
+func (d *discardWriter) Header() http.Header         { return d.header }
+func (d *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardWriter) WriteHeader(int)             {}
+
+type BenchService struct {
+	Module `base:"/bench"`
+	ping   Get `url:"/ping"`
+}
+
+func (s BenchService) Ping(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func plainPing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func benchHandler() func(w http.ResponseWriter, r *http.Request) {
+	service := &BenchService{}
+	field, _ := reflect.TypeOf(service).Elem().FieldByName("ping")
+	handler, _ := checkAPIMethodExists(reflect.ValueOf(service), field)
+	return *handler
+}
+
+// benchSpec describes BenchService the way `neon gen` would
+func benchSpec(s *BenchService) ServiceSpec {
+	return ServiceSpec{
+		Service: s,
+		Name:    "neon.BenchService",
+		Base:    "/bench",
+		Endpoints: []EndpointSpec{
+			{Method: http.MethodGet, Field: "ping", HandlerName: "Ping", Handler: func(w http.ResponseWriter, r *http.Request) { s.Ping(w, r) }, URL: "/ping"},
+		},
+	}
+}
+
+// Generated specs call handler methods directly; reflected services go
+// through reflect method values and allocate
+func TestHandlerDispatchAllocs(t *testing.T) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+
+	app := New()
+	spec := benchSpec(&BenchService{})
+	eps, errs := app.serviceEndpoints(&spec, nil)
+	if len(errs) > 0 || len(eps) != 1 {
+		t.Fatalf("Expected one endpoint, got %v %v", eps, errs)
+	}
+	handler := eps[0].handler
+	plain := http.HandlerFunc(plainPing)
+
+	got := testing.AllocsPerRun(100, func() { handler(w, req) })
+	want := testing.AllocsPerRun(100, func() { plain(w, req) })
+	if got != want {
+		t.Errorf("Expected %v allocs per request like http.HandlerFunc, got %v", want, got)
+	}
+}
+
+func TestServeHTTPAllocs(t *testing.T) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+
+	app := New()
+	app.AddServiceSpec(benchSpec(&BenchService{}))
+	app.Build()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /bench/ping", plainPing)
+
+	got := testing.AllocsPerRun(100, func() { app.ServeHTTP(w, req) })
+	want := testing.AllocsPerRun(100, func() { mux.ServeHTTP(w, req) })
+	if got != want {
+		t.Errorf("Expected %v allocs per request like http.ServeMux, got %v", want, got)
+	}
+}
+
+func BenchmarkPlainHandlerFunc(b *testing.B) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+	handler := http.HandlerFunc(plainPing)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		handler(w, req)
+	}
+}
+
+func BenchmarkHandlerDispatch(b *testing.B) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+	handler := benchHandler()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		handler(w, req)
+	}
+}
+
+func BenchmarkPlainServeMux(b *testing.B) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /bench/ping", plainPing)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mux.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkAppServeHTTPSpec(b *testing.B) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+	app := New()
+	app.AddServiceSpec(benchSpec(&BenchService{}))
+	app.Build()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		app.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkAppServeHTTP(b *testing.B) {
+	w := &discardWriter{header: http.Header{}}
+	req := httptest.NewRequest("GET", "/bench/ping", nil)
+	app := New()
+	app.AddService(&BenchService{})
+	app.Build()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		app.ServeHTTP(w, req)
+	}
+}
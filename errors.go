@@ -0,0 +1,77 @@
+package neon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RegistrationError : Describes a single service or endpoint that could not be registered
+type RegistrationError struct {
+	Service string // service type, e.g. "main.UserService", empty for App.Handle routes
+	Field   string // offending struct field, empty for service level problems; the route for App.Handle routes
+	Tag     string // offending struct tag, e.g. `middleware:"Auth"`
+	Reason  string
+}
+
+func (e *RegistrationError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Service)
+	if e.Field != "" {
+		// Routes added with App.Handle have no service
+		if e.Service != "" {
+			b.WriteString(".")
+		}
+		b.WriteString(e.Field)
+	}
+	if e.Tag != "" {
+		fmt.Fprintf(&b, " (%s)", e.Tag)
+	}
+	b.WriteString(": ")
+	b.WriteString(e.Reason)
+	return b.String()
+}
+
+// RegistrationErrors : Aggregates every registration problem found in one pass
+type RegistrationErrors []*RegistrationError
+
+func (e RegistrationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return fmt.Sprintf("neon: %d registration error(s):\n  %s", len(e), strings.Join(lines, "\n  "))
+}
+
+// Unwrap allows errors.As to reach individual *RegistrationError values
+func (e RegistrationErrors) Unwrap() []error {
+	out := make([]error, 0, len(e))
+	for _, err := range e {
+		out = append(out, err)
+	}
+	return out
+}
+
+// without returns the errors of e not also found in old
+func (e RegistrationErrors) without(old RegistrationErrors) RegistrationErrors {
+	seen := make(map[string]int, len(old))
+	for _, err := range old {
+		seen[err.Error()]++
+	}
+	var out RegistrationErrors
+	for _, err := range e {
+		if key := err.Error(); seen[key] > 0 {
+			seen[key]--
+			continue
+		}
+		out = append(out, err)
+	}
+	return out
+}
+
+// errOrNil avoids returning a non-nil error interface holding an empty slice
+func (e RegistrationErrors) errOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,125 @@
+package neon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type NoModuleService struct {
+	Module
+}
+
+type MissingMiddlewareService struct {
+	Module  `base:"/mw"`
+	getOpen Get `url:"/open"`
+	getAuth Get `url:"/auth" middleware:"Auth"`
+}
+
+func (s MissingMiddlewareService) GetOpen(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("open"))
+}
+
+func (s MissingMiddlewareService) GetAuth(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("secret"))
+}
+
+func TestAddServiceReturnsError(t *testing.T) {
+	app := New()
+
+	err := app.AddService(TestService{})
+	var errs RegistrationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected RegistrationErrors, got %v", err)
+	}
+	if len(errs) != 1 || errs[0].Service != "neon.TestService" {
+		t.Errorf("Expected one error for neon.TestService, got %v", errs)
+	}
+
+	if err := app.AddService(&TestService{}); err != nil {
+		t.Errorf("Expected valid service to be accepted, got %v", err)
+	}
+}
+
+func TestBuildAggregatesErrors(t *testing.T) {
+	app := New()
+	app.AddService(TestService{}) // not a pointer
+	app.AddService(&TestEndpointService{})
+	app.AddService(&MissingMiddlewareService{})
+
+	err := app.Build()
+	var errs RegistrationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected RegistrationErrors, got %v", err)
+	}
+
+	expected := map[string]string{
+		"neon.TestService":                        "pointer",
+		"neon.TestEndpointService.nonExistent":    "handler method NonExistent not found",
+		"neon.TestEndpointService.UppercaseField": "lowercase",
+		"neon.MissingMiddlewareService.getAuth":   "middleware not registered: Auth",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d: %v", len(expected), len(errs), err)
+	}
+	for _, e := range errs {
+		key := e.Service
+		if e.Field != "" {
+			key += "." + e.Field
+		}
+		want, ok := expected[key]
+		if !ok || !strings.Contains(e.Reason, want) {
+			t.Errorf("Unexpected error %v", e)
+		}
+	}
+
+	// errors.As also reaches individual errors
+	var single *RegistrationError
+	if !errors.As(err, &single) {
+		t.Error("Expected errors.As to find a *RegistrationError")
+	}
+
+	// Valid endpoints are still served, broken ones are dropped
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/mw/open", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for valid endpoint, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/mw/auth", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected endpoint with missing middleware to be dropped, got %d", w.Code)
+	}
+}
+
+func TestBuildWithoutErrors(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+
+	if err := app.Build(); err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+	}
+}
+
+func TestStrictModeRefusesToStart(t *testing.T) {
+	app := New(&Config{Port: freePort(t)})
+	app.Strict = true
+	app.AddService(&MissingMiddlewareService{})
+
+	err := app.RunContext(context.Background())
+	var errs RegistrationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected RunContext to fail with RegistrationErrors, got %v", err)
+	}
+}
+
+func TestRegistrationErrorString(t *testing.T) {
+	err := &RegistrationError{Service: "main.Svc", Field: "getX", Tag: `middleware:"A"`, Reason: "bad"}
+	if got := err.Error(); got != `main.Svc.getX (middleware:"A"): bad` {
+		t.Errorf("Unexpected error string %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+package neon
+
+import "strings"
+
+// Group : A set of services sharing a path prefix and middlewares
+// Groups nest; prefixes and middlewares compose from the outermost group in
+type Group struct {
+	app         *App
+	parent      *Group
+	prefix      string
+	middlewares []Middleware
+}
+
+// Group creates a route group under prefix; mws run after global middlewares
+// and before module middlewares of every service added to the group
+func (s *App) Group(prefix string, mws ...Middleware) *Group {
+	return &Group{app: s, prefix: cleanPrefix(prefix), middlewares: mws}
+}
+
+// Group creates a nested group whose prefix and middlewares follow g's
+func (g *Group) Group(prefix string, mws ...Middleware) *Group {
+	return &Group{app: g.app, parent: g, prefix: cleanPrefix(prefix), middlewares: mws}
+}
+
+// Use adds middlewares to the group
+// Like App.AddMiddleware it also applies to services added earlier, from the next Build
+func (g *Group) Use(mws ...Middleware) {
+	g.app.mu.Lock()
+	defer g.app.mu.Unlock()
+	g.middlewares = append(g.middlewares, mws...)
+}
+
+// AddService : Add Service to the group, see App.AddService
+func (g *Group) AddService(servicePtr Moduler) error {
+	return g.app.addService(servicePtr, g)
+}
+
+// fullPrefix returns the composed prefix of g and its parents, "" for no group
+func (g *Group) fullPrefix() string {
+	if g == nil {
+		return ""
+	}
+	return g.parent.fullPrefix() + g.prefix
+}
+
+// middlewareChain returns the composed middlewares of g and its parents, outermost first
+func (g *Group) middlewareChain() []Middleware {
+	if g == nil {
+		return nil
+	}
+	return append(g.parent.middlewareChain(), g.middlewares...)
+}
+
+// cleanPrefix normalizes a prefix to "/a/b" form, "" for the root
+func cleanPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
@@ -0,0 +1,84 @@
+package neon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func recordingMiddleware(name string, order *[]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*order = append(*order, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	app := New()
+	var order []string
+
+	app.AddMiddleware(recordingMiddleware("global", &order))
+	app.RegisterMiddleware("Auth", recordingMiddleware("auth", &order))
+	app.RegisterMiddleware("RateLimit", recordingMiddleware("rateLimit", &order))
+
+	api := app.Group("/api/", recordingMiddleware("api", &order))
+	internal := api.Group("internal")
+	internal.AddService(&IntegrationTestService{})
+	// Use applies to services added earlier too
+	internal.Use(recordingMiddleware("internal", &order))
+
+	app.AddService(&TestService{})
+
+	w := serve(app, httptest.NewRequest("POST", "/api/internal/integration/create", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	expected := []string{"global", "api", "internal", "auth", "rateLimit"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected middleware order %v, got %v", expected, order)
+	}
+
+	// Services outside the group are unaffected
+	order = nil
+	w = serve(app, httptest.NewRequest("GET", "/test/endpoint", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !reflect.DeepEqual(order, []string{"global"}) {
+		t.Errorf("Expected only global middleware, got %v", order)
+	}
+
+	w = serve(app, httptest.NewRequest("POST", "/integration/create", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected grouped service to be served only under its prefix, got %d", w.Code)
+	}
+}
+
+func TestGroupRouteInfo(t *testing.T) {
+	app := New()
+	app.Group("/v1", passThrough).AddService(&TestService{})
+
+	routes := app.Routes()
+	if len(routes) != 1 {
+		t.Fatalf("Expected 1 route, got %v", routes)
+	}
+	if routes[0].Path != "/v1/test/endpoint" {
+		t.Errorf("Expected path /v1/test/endpoint, got %s", routes[0].Path)
+	}
+	if !reflect.DeepEqual(routes[0].GroupMiddlewares, []string{"neon.passThrough"}) {
+		t.Errorf("Unexpected group middlewares %v", routes[0].GroupMiddlewares)
+	}
+}
+
+func TestCleanPrefix(t *testing.T) {
+	tests := map[string]string{"": "", "/": "", "api": "/api", "/api/": "/api", "/api/v1": "/api/v1"}
+	for in, expected := range tests {
+		if got := cleanPrefix(in); got != expected {
+			t.Errorf("cleanPrefix(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package neon
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Handle registers handler for method and path, next to struct-tag services.
+// path accepts everything a `url` tag does, including typed wildcards, and
+// middlewares names middlewares registered with RegisterMiddleware like the
+// `middleware` tag. The route goes through the same middleware, conflict
+// detection and Routes listing as service endpoints.
+// method may be MethodAny to serve every method.
+func (s *App) Handle(method, path string, handler http.Handler, middlewares ...string) error {
+	return s.handle(strings.ToUpper(method), path, handler.ServeHTTP, fmt.Sprintf("%T", handler), middlewares)
+}
+
+// Get registers fn for GET requests on path, see Handle
+func (s *App) Get(path string, fn http.HandlerFunc, middlewares ...string) error {
+	return s.handle(http.MethodGet, path, fn, funcName(fn), middlewares)
+}
+
+// Head registers fn for HEAD requests on path, see Handle
+func (s *App) Head(path string, fn http.HandlerFunc, middlewares ...string) error {
+	return s.handle(http.MethodHead, path, fn, funcName(fn), middlewares)
+}
+
+// Post registers fn for POST requests on path, see Handle
+func (s *App) Post(path string, fn http.HandlerFunc, middlewares ...string) error {
+	return s.handle(http.MethodPost, path, fn, funcName(fn), middlewares)
+}
+
+// Put registers fn for PUT requests on path, see Handle
+func (s *App) Put(path string, fn http.HandlerFunc, middlewares ...string) error {
+	return s.handle(http.MethodPut, path, fn, funcName(fn), middlewares)
+}
+
+// Patch registers fn for PATCH requests on path, see Handle
+func (s *App) Patch(path string, fn http.HandlerFunc, middlewares ...string) error {
+	return s.handle(http.MethodPatch, path, fn, funcName(fn), middlewares)
+}
+
+// Delete registers fn for DELETE requests on path, see Handle
+func (s *App) Delete(path string, fn http.HandlerFunc, middlewares ...string) error {
+	return s.handle(http.MethodDelete, path, fn, funcName(fn), middlewares)
+}
+
+// handle adds a route as a single endpoint service; the route itself names
+// it in registration errors
+func (s *App) handle(method, path string, fn http.HandlerFunc, handlerName string, middlewares []string) error {
+	return s.addServiceSpec(ServiceSpec{
+		Endpoints: []EndpointSpec{{
+			Method:      method,
+			Field:       routeID(method, path),
+			HandlerName: handlerName,
+			Handler:     fn,
+			URL:         path,
+			Middleware:  strings.Join(middlewares, ","),
+		}},
+	}, nil)
+}
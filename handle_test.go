@@ -0,0 +1,114 @@
+package neon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func health(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+type echoHandler struct{}
+
+func (echoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(r.Method + " " + r.PathValue("id")))
+}
+
+func TestFunctionalRoutes(t *testing.T) {
+	app := New()
+	var order []string
+	app.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "global")
+			next.ServeHTTP(w, r)
+		})
+	})
+	app.RegisterMiddleware("Auth", func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "auth")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	app.Get("/health", health)
+	app.Post("/items/{id:int}", echoHandler{}.ServeHTTP, "Auth")
+	app.Handle(MethodAny, "/echo/{id}", echoHandler{})
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	w := serve(app, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("Expected 200 'ok', got %d '%s'", w.Code, w.Body.String())
+	}
+
+	order = nil
+	w = serve(app, httptest.NewRequest("POST", "/items/7", nil))
+	if w.Body.String() != "POST 7" || strings.Join(order, ",") != "global,auth" {
+		t.Errorf("Expected 'POST 7' through global,auth; got '%s' through %v", w.Body.String(), order)
+	}
+	if w := serve(app, httptest.NewRequest("POST", "/items/x", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected typed wildcard to reject 'x', got %d", w.Code)
+	}
+	if w := serve(app, httptest.NewRequest("PATCH", "/echo/3", nil)); w.Body.String() != "PATCH 3" {
+		t.Errorf("Expected 'PATCH 3', got '%s'", w.Body.String())
+	}
+
+	routes := map[string]RouteInfo{}
+	for _, rt := range app.Routes() {
+		routes[rt.Method+" "+rt.Path] = rt
+	}
+	if rt := routes["GET /health"]; rt.Handler != "neon.health" || rt.Service != "" {
+		t.Errorf("Expected GET /health served by neon.health, got %+v", rt)
+	}
+	if rt := routes["POST /items/{id}"]; len(rt.EndpointMiddlewares) != 1 || rt.EndpointMiddlewares[0] != "Auth" {
+		t.Errorf("Expected Auth endpoint middleware on POST /items/{id}, got %+v", rt)
+	}
+	if rt := routes["* /echo/{id}"]; rt.Handler != "neon.echoHandler" {
+		t.Errorf("Expected * /echo/{id} served by neon.echoHandler, got %+v", rt)
+	}
+}
+
+func TestFunctionalRouteErrors(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+	app.Get("/test/endpoint", health)
+	app.Get("/private", health, "Missing")
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	if errs[0].Error() != `GET /private (middleware:"Missing"): middleware not registered: Missing` {
+		t.Errorf("Unexpected middleware error %q", errs[0].Error())
+	}
+	if errs[1].Field != "GET /test/endpoint" || !strings.Contains(errs[1].Reason, "neon.TestService.getTest") {
+		t.Errorf("Expected conflict with neon.TestService.getTest, got %v", errs[1])
+	}
+
+	// The service registered first keeps the route
+	if w := serve(app, httptest.NewRequest("GET", "/test/endpoint", nil)); w.Body.String() != "test response" {
+		t.Errorf("Expected 'test response', got '%s'", w.Body.String())
+	}
+}
+
+func TestFunctionalRouteAtRuntime(t *testing.T) {
+	app := New()
+	app.Build()
+
+	if err := app.Get("/health", health); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/health", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if err := app.DisableRoute("GET", "/health"); err != nil {
+		t.Fatalf("DisableRoute failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/health", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package neon
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// hostPattern : A host template such as "{tenant}.api.example.com"
+// Each {name} label matches exactly one DNS label; literals match case-insensitively
+type hostPattern struct {
+	template string
+	labels   []string
+}
+
+// parseHost validates a host tag, nil for an empty tag
+func parseHost(template string) (*hostPattern, error) {
+	if template == "" {
+		return nil, nil
+	}
+	labels := strings.Split(strings.ToLower(template), ".")
+	for _, label := range labels {
+		if label == "" {
+			return nil, fmt.Errorf("empty label in host %q", template)
+		}
+		if strings.ContainsAny(label, "{}") && !isHostWildcard(label) {
+			return nil, fmt.Errorf("wildcards must span a whole label in host %q", template)
+		}
+	}
+	// Keep the original case of wildcard names
+	original := strings.Split(template, ".")
+	for i, label := range labels {
+		if isHostWildcard(label) {
+			labels[i] = original[i]
+		}
+	}
+	return &hostPattern{template: template, labels: labels}, nil
+}
+
+func isHostWildcard(label string) bool {
+	return len(label) > 2 && label[0] == '{' && label[len(label)-1] == '}' &&
+		!strings.ContainsAny(label[1:len(label)-1], "{}")
+}
+
+// match reports whether host (optionally with a port) fits the template,
+// returning the wildcard names and the values they matched
+func (h *hostPattern) match(host string) (names, values []string, ok bool) {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	if len(labels) != len(h.labels) {
+		return nil, nil, false
+	}
+	for i, label := range h.labels {
+		if isHostWildcard(label) {
+			if labels[i] == "" {
+				return nil, nil, false
+			}
+			names = append(names, label[1:len(label)-1])
+			values = append(values, labels[i])
+			continue
+		}
+		if !strings.EqualFold(label, labels[i]) {
+			return nil, nil, false
+		}
+	}
+	return names, values, true
+}
+
+// shape erases wildcard names so templates matching the same hosts compare equal
+func (h *hostPattern) shape() string {
+	labels := make([]string, len(h.labels))
+	for i, label := range h.labels {
+		if isHostWildcard(label) {
+			label = "{}"
+		}
+		labels[i] = label
+	}
+	return strings.Join(labels, ".")
+}
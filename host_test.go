@@ -0,0 +1,135 @@
+package neon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type TenantService struct {
+	Module     `base:"/profile" host:"{tenant}.api.example.com"`
+	getProfile Get `url:"/{id}"`
+	getStatus  Get `url:"/status" host:"admin.example.com"`
+}
+
+func (s TenantService) GetProfile(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("tenant " + r.PathValue("tenant") + " profile " + r.PathValue("id")))
+}
+
+func (s TenantService) GetStatus(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("admin status"))
+}
+
+type PublicProfileService struct {
+	Module     `base:"/profile"`
+	getProfile Get `url:"/{user}"`
+}
+
+func (s PublicProfileService) GetProfile(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("public profile " + r.PathValue("user")))
+}
+
+func TestHostRouting(t *testing.T) {
+	app := New()
+	// Host-less fallback registered first still loses to the matching host
+	app.AddService(&PublicProfileService{})
+	app.AddService(&TenantService{})
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	tests := []struct {
+		host     string
+		path     string
+		code     int
+		expected string
+	}{
+		{"acme.api.example.com", "/profile/42", http.StatusOK, "tenant acme profile 42"},
+		{"ACME.api.example.com:8443", "/profile/42", http.StatusOK, "tenant ACME profile 42"},
+		{"www.example.com", "/profile/42", http.StatusOK, "public profile 42"},
+		{"a.b.api.example.com", "/profile/42", http.StatusOK, "public profile 42"},
+		{"admin.example.com", "/profile/status", http.StatusOK, "admin status"},
+		// The more specific pattern wins in the mux before hosts are compared
+		{"acme.api.example.com", "/profile/status", http.StatusNotFound, "404 page not found\n"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		req.Host = test.host
+		w := serve(app, req)
+		if w.Code != test.code {
+			t.Errorf("%s%s: expected status %d, got %d", test.host, test.path, test.code, w.Code)
+			continue
+		}
+		if w.Body.String() != test.expected {
+			t.Errorf("%s%s: expected '%s', got '%s'", test.host, test.path, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestHostRoutingWithoutFallback(t *testing.T) {
+	app := New()
+	app.AddService(&TenantService{})
+
+	req := httptest.NewRequest("GET", "/profile/42", nil)
+	req.Host = "example.org"
+	if w := serve(app, req); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unmatched host, got %d", w.Code)
+	}
+
+	for _, rt := range app.Routes() {
+		if rt.Handler == "GetStatus" && rt.Host != "admin.example.com" {
+			t.Errorf("Expected endpoint host to override module host, got %q", rt.Host)
+		}
+	}
+}
+
+type DuplicateTenantService struct {
+	Module     `base:"/profile" host:"{org}.api.example.com"`
+	getProfile Get `url:"/{id}"`
+}
+
+func (s DuplicateTenantService) GetProfile(w http.ResponseWriter, r *http.Request) {}
+
+type BadHostService struct {
+	Module `host:"api.{x}y.com"`
+	getX   Get `url:"/x"`
+}
+
+func (s BadHostService) GetX(w http.ResponseWriter, r *http.Request) {}
+
+func TestHostConflictsAndErrors(t *testing.T) {
+	app := New()
+	app.AddService(&TenantService{})
+	app.AddService(&DuplicateTenantService{}) // same route on the same host
+	app.AddService(&BadHostService{})
+
+	var errs RegistrationErrors
+	if !errors.As(app.Build(), &errs) || len(errs) != 2 {
+		t.Fatalf("Expected 2 registration errors, got %v", errs)
+	}
+	if errs[0].Service != "neon.BadHostService" || errs[1].Service != "neon.DuplicateTenantService" {
+		t.Errorf("Unexpected errors %v", errs)
+	}
+}
+
+func TestHostPatternMatch(t *testing.T) {
+	h, err := parseHost("{tenant}.api.example.com")
+	if err != nil {
+		t.Fatalf("parseHost failed: %v", err)
+	}
+
+	names, values, ok := h.match("acme.API.example.com.")
+	if !ok || len(names) != 1 || names[0] != "tenant" || values[0] != "acme" {
+		t.Errorf("Unexpected match %v %v %v", names, values, ok)
+	}
+
+	if _, _, ok := h.match("api.example.com"); ok {
+		t.Error("Expected host with missing label not to match")
+	}
+
+	if h, err := parseHost(""); h != nil || err != nil {
+		t.Error("Expected empty host tag to produce no pattern")
+	}
+}
@@ -1,10 +1,73 @@
 package neon
 
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+)
+
 type API interface{}
 
 type Get API
+type Head API
 type Put API
 type Post API
 type Patch API
 type Delete API
 type Options API
+type Trace API
+type Connect API
+
+// Any matches every HTTP method not served by a more specific endpoint on the same path
+type Any API
+
+// MethodAny is the route table method used by Any endpoints and EndpointSpec
+const MethodAny = "*"
+
+// methodTypes maps endpoint marker types to the HTTP method they serve
+var methodTypes = map[reflect.Type]string{
+	reflect.TypeOf((*Get)(nil)).Elem():     http.MethodGet,
+	reflect.TypeOf((*Head)(nil)).Elem():    http.MethodHead,
+	reflect.TypeOf((*Put)(nil)).Elem():     http.MethodPut,
+	reflect.TypeOf((*Post)(nil)).Elem():    http.MethodPost,
+	reflect.TypeOf((*Patch)(nil)).Elem():   http.MethodPatch,
+	reflect.TypeOf((*Delete)(nil)).Elem():  http.MethodDelete,
+	reflect.TypeOf((*Options)(nil)).Elem(): http.MethodOptions,
+	reflect.TypeOf((*Trace)(nil)).Elem():   http.MethodTrace,
+	reflect.TypeOf((*Connect)(nil)).Elem(): http.MethodConnect,
+	reflect.TypeOf((*Any)(nil)).Elem():     MethodAny,
+}
+
+// standardMethods are advertised in Allow for paths served by an Any endpoint
+var standardMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// endpointMethod returns the HTTP method for an endpoint field type
+func endpointMethod(t reflect.Type) (string, bool) {
+	method, ok := methodTypes[t]
+	return method, ok
+}
+
+// allowedMethods computes the Allow header value for a set of registered methods,
+// including the automatic HEAD and OPTIONS responses
+func allowedMethods(handlers map[string]http.HandlerFunc) string {
+	if _, ok := handlers[MethodAny]; ok {
+		return strings.Join(standardMethods, ", ")
+	}
+	set := map[string]bool{http.MethodOptions: true}
+	for method := range handlers {
+		set[method] = true
+	}
+	if set[http.MethodGet] {
+		set[http.MethodHead] = true
+	}
+	methods := make([]string, 0, len(set))
+	for method := range set {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
@@ -1,6 +1,9 @@
 package neon
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 )
@@ -17,6 +20,10 @@ func TestHTTPMethodTypes(t *testing.T) {
 		{"Patch type", (*Patch)(nil), "*neon.Patch"},
 		{"Delete type", (*Delete)(nil), "*neon.Delete"},
 		{"Options type", (*Options)(nil), "*neon.Options"},
+		{"Head type", (*Head)(nil), "*neon.Head"},
+		{"Trace type", (*Trace)(nil), "*neon.Trace"},
+		{"Connect type", (*Connect)(nil), "*neon.Connect"},
+		{"Any type", (*Any)(nil), "*neon.Any"},
 	}
 
 	for _, test := range tests {
@@ -29,3 +36,149 @@ func TestHTTPMethodTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestEndpointMethod(t *testing.T) {
+	tests := []struct {
+		field    interface{}
+		expected string
+	}{
+		{(*Get)(nil), "GET"},
+		{(*Head)(nil), "HEAD"},
+		{(*Trace)(nil), "TRACE"},
+		{(*Connect)(nil), "CONNECT"},
+		{(*Any)(nil), "*"},
+	}
+
+	for _, test := range tests {
+		method, ok := endpointMethod(reflect.TypeOf(test.field).Elem())
+		if !ok || method != test.expected {
+			t.Errorf("Expected %s, got %s (ok=%v)", test.expected, method, ok)
+		}
+	}
+
+	if _, ok := endpointMethod(reflect.TypeOf("")); ok {
+		t.Error("Expected non-endpoint type to be rejected")
+	}
+}
+
+type MethodsService struct {
+	Module     `base:"/items"`
+	listItems  Get     `url:"/"`
+	headStatus Head    `url:"/status"`
+	addItem    Post    `url:"/"`
+	fallback   Any     `url:"/any"`
+	getAny     Get     `url:"/any"`
+	optsCustom Options `url:"/custom"`
+}
+
+func (s MethodsService) ListItems(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Items", "3")
+	w.Write([]byte("a,b,c"))
+}
+
+func (s MethodsService) HeadStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Status", "ok")
+}
+
+func (s MethodsService) AddItem(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s MethodsService) Fallback(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("any " + r.Method))
+}
+
+func (s MethodsService) GetAny(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("get"))
+}
+
+func (s MethodsService) OptsCustom(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("custom options"))
+}
+
+func TestAutomaticHeadAndOptions(t *testing.T) {
+	app := New()
+	app.AddService(&MethodsService{})
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	t.Run("HEAD served from GET without body", func(t *testing.T) {
+		srv := httptest.NewServer(app)
+		defer srv.Close()
+		resp, err := http.Head(srv.URL + "/items/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", resp.StatusCode)
+		}
+		if resp.Header.Get("X-Items") != "3" {
+			t.Error("Expected GET headers on HEAD response")
+		}
+		if resp.ContentLength != 5 {
+			t.Errorf("Expected Content-Length 5 like GET, got %d", resp.ContentLength)
+		}
+		if len(body) != 0 {
+			t.Errorf("Expected empty body, got '%s'", body)
+		}
+	})
+
+	t.Run("Explicit HEAD endpoint", func(t *testing.T) {
+		w := serve(app, httptest.NewRequest("HEAD", "/items/status", nil))
+		if w.Header().Get("X-Status") != "ok" {
+			t.Error("Expected explicit HEAD handler to run")
+		}
+	})
+
+	t.Run("Automatic OPTIONS", func(t *testing.T) {
+		w := serve(app, httptest.NewRequest("OPTIONS", "/items/", nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+		if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS, POST" {
+			t.Errorf("Unexpected Allow header %q", allow)
+		}
+	})
+
+	t.Run("Automatic OPTIONS keeps middleware state", func(t *testing.T) {
+		app := New()
+		constructed := 0
+		app.AddMiddleware(func(next http.Handler) http.Handler {
+			constructed++
+			return next
+		})
+		app.AddService(&MethodsService{})
+		app.Build()
+
+		before := constructed
+		for i := 0; i < 3; i++ {
+			serve(app, httptest.NewRequest("OPTIONS", "/items/", nil))
+		}
+		if constructed != before {
+			t.Errorf("Expected global middlewares to be wrapped once per route, got %d extra constructions", constructed-before)
+		}
+	})
+
+	t.Run("Explicit OPTIONS endpoint", func(t *testing.T) {
+		w := serve(app, httptest.NewRequest("OPTIONS", "/items/custom", nil))
+		if w.Body.String() != "custom options" {
+			t.Errorf("Expected explicit OPTIONS handler, got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("Any endpoint", func(t *testing.T) {
+		w := serve(app, httptest.NewRequest("DELETE", "/items/any", nil))
+		if w.Body.String() != "any DELETE" {
+			t.Errorf("Expected Any handler, got '%s'", w.Body.String())
+		}
+
+		// A specific method takes precedence over Any
+		w = serve(app, httptest.NewRequest("GET", "/items/any", nil))
+		if w.Body.String() != "get" {
+			t.Errorf("Expected GET handler, got '%s'", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package neon
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// provider : A dependency registered with App.Provide
+type provider struct {
+	name string
+	typ  reflect.Type
+
+	// ctor builds the value on first use; invalid for provided values
+	ctor  reflect.Value
+	value reflect.Value
+	ready bool
+}
+
+func (p *provider) String() string {
+	if p.name == "" {
+		return p.typ.String()
+	}
+	return fmt.Sprintf("%s %q", p.typ, p.name)
+}
+
+// ProvideOption : Configures a provided dependency
+type ProvideOption func(*provider)
+
+// Named registers the dependency under name, injected into fields tagged `inject:"name"`
+func Named(name string) ProvideOption {
+	return func(p *provider) {
+		p.name = name
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// Provide registers a dependency for service fields tagged `inject:""`.
+// v is either a value or a constructor: a func returning the value and
+// optionally an error, whose parameters are themselves resolved from the
+// provided dependencies. Constructors run once, when first needed while
+// building, and must not call App methods. To provide a func value, wrap it
+// in a constructor.
+// Fields are matched by type; an interface field also accepts the single
+// provider implementing it. Tagged fields must be exported.
+func (s *App) Provide(v interface{}, opts ...ProvideOption) error {
+	if v == nil {
+		return errors.New("neon: Provide of nil")
+	}
+	p := &provider{typ: reflect.TypeOf(v), value: reflect.ValueOf(v), ready: true}
+	if p.typ.Kind() == reflect.Func {
+		ft := p.typ
+		if ft.NumOut() == 0 || ft.NumOut() > 2 || (ft.NumOut() == 2 && ft.Out(1) != errorType) || ft.IsVariadic() {
+			return fmt.Errorf("neon: constructor %s must return a value and optionally an error", ft)
+		}
+		p.ctor, p.typ, p.value, p.ready = p.value, ft.Out(0), reflect.Value{}, false
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	s.mu.RLock()
+	for _, q := range s.providers {
+		if q.typ == p.typ && q.name == p.name {
+			s.mu.RUnlock()
+			return fmt.Errorf("neon: %s is already provided", p)
+		}
+	}
+	s.mu.RUnlock()
+
+	// Services left unwired by a previous build pick the provider up
+	return s.update(func() {
+		s.providers = append(s.providers, p)
+	})
+}
+
+// injectService sets the `inject` tagged fields of service, once
+// Nothing is set unless every field resolves
+// Must be called with s.mu held
+func (s *App) injectService(service Moduler) RegistrationErrors {
+	if s.injected[service] {
+		return nil
+	}
+	sv := reflect.ValueOf(service).Elem()
+	st := sv.Type()
+
+	var errs RegistrationErrors
+	values := make(map[int]reflect.Value)
+	for i := 0; i < st.NumField(); i++ {
+		ft := st.Field(i)
+		name, ok := ft.Tag.Lookup("inject")
+		if !ok {
+			continue
+		}
+		if !ft.IsExported() {
+			errs = append(errs, &RegistrationError{
+				Service: st.String(),
+				Field:   ft.Name,
+				Tag:     fmt.Sprintf("inject:%q", name),
+				Reason:  "inject fields must be exported",
+			})
+			continue
+		}
+		v, err := s.resolve(ft.Type, name, nil)
+		if err != nil {
+			errs = append(errs, &RegistrationError{
+				Service: st.String(),
+				Field:   ft.Name,
+				Tag:     fmt.Sprintf("inject:%q", name),
+				Reason:  err.Error(),
+			})
+			continue
+		}
+		values[i] = v
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	for i, v := range values {
+		sv.Field(i).Set(v)
+	}
+	s.injected[service] = true
+	return nil
+}
+
+// resolve returns the value provided for typ and name, running constructors
+// as needed; path holds the constructors being run, to detect cycles
+func (s *App) resolve(typ reflect.Type, name string, path []*provider) (reflect.Value, error) {
+	p, err := s.lookupProvider(typ, name)
+	if err != nil {
+		if len(path) > 0 {
+			err = fmt.Errorf("%w, required by %s", err, path[len(path)-1])
+		}
+		return reflect.Value{}, err
+	}
+	if p.ready {
+		return p.value, nil
+	}
+	for i, q := range path {
+		if q == p {
+			chain := make([]string, 0, len(path)-i+1)
+			for _, c := range path[i:] {
+				chain = append(chain, c.String())
+			}
+			chain = append(chain, p.String())
+			return reflect.Value{}, errors.New("dependency cycle: " + strings.Join(chain, " -> "))
+		}
+	}
+
+	path = append(path, p)
+	ft := p.ctor.Type()
+	args := make([]reflect.Value, ft.NumIn())
+	for i := range args {
+		if args[i], err = s.resolve(ft.In(i), "", path); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	out := p.ctor.Call(args)
+	if len(out) == 2 && !out[1].IsNil() {
+		return reflect.Value{}, fmt.Errorf("constructor for %s: %w", p, out[1].Interface().(error))
+	}
+	p.value, p.ready = out[0], true
+	return p.value, nil
+}
+
+// lookupProvider finds the provider for typ and name, preferring an exact type
+// match over a single provider implementing an interface type
+func (s *App) lookupProvider(typ reflect.Type, name string) (*provider, error) {
+	var implementing []*provider
+	for _, p := range s.providers {
+		if p.name != name {
+			continue
+		}
+		if p.typ == typ {
+			return p, nil
+		}
+		if typ.Kind() == reflect.Interface && p.typ.Implements(typ) {
+			implementing = append(implementing, p)
+		}
+	}
+	switch {
+	case len(implementing) == 1:
+		return implementing[0], nil
+	case len(implementing) > 1:
+		names := make([]string, 0, len(implementing))
+		for _, p := range implementing {
+			names = append(names, p.String())
+		}
+		return nil, fmt.Errorf("ambiguous dependency %s: provided by %s", typ, strings.Join(names, ", "))
+	case name != "":
+		return nil, fmt.Errorf("no provider named %q for %s", name, typ)
+	default:
+		return nil, fmt.Errorf("no provider for %s", typ)
+	}
+}
@@ -0,0 +1,217 @@
+package neon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testDB struct{ dsn string }
+
+type testRepo struct{ db *testDB }
+
+func newTestRepo(db *testDB) *testRepo { return &testRepo{db: db} }
+
+type greeter interface{ Greet() string }
+
+type englishGreeter struct{}
+
+func (englishGreeter) Greet() string { return "hello" }
+
+type WiredService struct {
+	Module   `base:"/wired"`
+	getWired Get `url:"/"`
+
+	Repo    *testRepo `inject:""`
+	Replica *testDB   `inject:"replica"`
+	Greeter greeter   `inject:""`
+	plain   *testDB   // not injected
+}
+
+func (s *WiredService) GetWired(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(s.Greeter.Greet() + " " + s.Repo.db.dsn + " " + s.Replica.dsn))
+}
+
+func TestInjectServiceFields(t *testing.T) {
+	app := New()
+	if err := app.Provide(&testDB{dsn: "primary"}); err != nil {
+		t.Fatalf("Provide failed: %v", err)
+	}
+	app.Provide(&testDB{dsn: "replica"}, Named("replica"))
+	app.Provide(newTestRepo)
+	app.Provide(englishGreeter{})
+
+	service := &WiredService{}
+	app.AddService(service)
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	w := serve(app, httptest.NewRequest("GET", "/wired/", nil))
+	if w.Body.String() != "hello primary replica" {
+		t.Errorf("Expected 'hello primary replica', got '%s'", w.Body.String())
+	}
+	if service.plain != nil {
+		t.Error("Expected untagged field to be left alone")
+	}
+
+	// Constructors run once and their value is shared
+	other := &WiredService{}
+	app.AddService(other)
+	if other.Repo != service.Repo {
+		t.Error("Expected the constructed repo to be shared between services")
+	}
+}
+
+func TestInjectUnresolved(t *testing.T) {
+	app := New()
+	app.Provide(newTestRepo) // needs *testDB, which is not provided
+	app.Provide(englishGreeter{})
+	service := &WiredService{}
+	app.AddService(service)
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 2 {
+		t.Fatalf("Expected errors for Repo and Replica, got %v", errs)
+	}
+	if errs[0].Field != "Repo" || errs[0].Reason != "no provider for *neon.testDB, required by *neon.testRepo" {
+		t.Errorf("Unexpected error %v", errs[0])
+	}
+	if errs[1].Field != "Replica" || errs[1].Reason != `no provider named "replica" for *neon.testDB` {
+		t.Errorf("Unexpected error %v", errs[1])
+	}
+	if service.Repo != nil {
+		t.Error("Expected no field to be set when a dependency is missing")
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/wired/", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected unwired service to be skipped, got %d", w.Code)
+	}
+
+	// Providing the missing dependencies wires the service on the rebuild
+	app.Provide(&testDB{dsn: "primary"})
+	if err := app.Provide(&testDB{dsn: "replica"}, Named("replica")); err != nil {
+		t.Fatalf("Expected rebuild to succeed, got %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/wired/", nil)); w.Body.String() != "hello primary replica" {
+		t.Errorf("Expected 'hello primary replica', got '%s'", w.Body.String())
+	}
+}
+
+type UnexportedInjectService struct {
+	Module `base:"/unexported"`
+	db     *testDB `inject:""`
+}
+
+func TestInjectUnexportedField(t *testing.T) {
+	app := New()
+	app.Provide(&testDB{})
+	service := &UnexportedInjectService{}
+	app.AddService(service)
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 || errs[0].Field != "db" || errs[0].Reason != "inject fields must be exported" {
+		t.Errorf("Expected unexported field error, got %v", errs)
+	}
+	if service.db != nil {
+		t.Error("Expected unexported field to be left alone")
+	}
+}
+
+type cycleA struct{}
+type cycleB struct{}
+
+type CycleService struct {
+	Module `base:"/cycle"`
+	A      *cycleA `inject:""`
+}
+
+func TestInjectCycle(t *testing.T) {
+	app := New()
+	app.Provide(func(b *cycleB) *cycleA { return &cycleA{} })
+	app.Provide(func(a *cycleA) *cycleB { return &cycleB{} })
+	app.AddService(&CycleService{})
+
+	errs := conflictErrors(t, app.Build())
+	want := "dependency cycle: *neon.cycleA -> *neon.cycleB -> *neon.cycleA"
+	if len(errs) != 1 || errs[0].Reason != want {
+		t.Errorf("Expected %q, got %v", want, errs)
+	}
+}
+
+func TestInjectConstructorError(t *testing.T) {
+	app := New()
+	dialErr := errors.New("dial failed")
+	app.Provide(func() (*testDB, error) { return nil, dialErr })
+	app.Provide(newTestRepo)
+	app.Provide(englishGreeter{})
+
+	app.Provide(&testDB{}, Named("replica"))
+	app.AddService(&WiredService{})
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 || errs[0].Reason != "constructor for *neon.testDB: dial failed" {
+		t.Errorf("Expected constructor error, got %v", errs)
+	}
+}
+
+func TestProvideValidation(t *testing.T) {
+	app := New()
+	if err := app.Provide(nil); err == nil {
+		t.Error("Expected error providing nil")
+	}
+	if err := app.Provide(func() {}); err == nil {
+		t.Error("Expected error for constructor without result")
+	}
+	if err := app.Provide(func() (*testDB, string) { return nil, "" }); err == nil {
+		t.Error("Expected error for constructor whose second result is not an error")
+	}
+	app.Provide(&testDB{})
+	if err := app.Provide(func() *testDB { return nil }); err == nil || !strings.Contains(err.Error(), "already provided") {
+		t.Errorf("Expected duplicate provider error, got %v", err)
+	}
+	if err := app.Provide(&testDB{}, Named("other")); err != nil {
+		t.Errorf("Expected named provider of the same type to be accepted, got %v", err)
+	}
+}
+
+type ambiguousGreeter struct{}
+
+func (ambiguousGreeter) Greet() string { return "hi" }
+
+type GreeterService struct {
+	Module  `base:"/greeter"`
+	Greeter greeter `inject:""`
+	ready   bool
+}
+
+func (s *GreeterService) Init(ctx context.Context, app *App) error {
+	// Dependencies are injected before Init
+	s.ready = s.Greeter != nil
+	return nil
+}
+
+func TestInjectInterface(t *testing.T) {
+	app := New()
+	app.Provide(englishGreeter{})
+	app.Provide(ambiguousGreeter{})
+	app.AddService(&GreeterService{})
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Reason, "ambiguous dependency neon.greeter") {
+		t.Errorf("Expected ambiguous dependency error, got %v", errs)
+	}
+
+	app = New()
+	app.Provide(englishGreeter{})
+	service := &GreeterService{}
+	app.AddService(service)
+	if err := app.InitServices(context.Background()); err != nil {
+		t.Fatalf("InitServices failed: %v", err)
+	}
+	if !service.ready {
+		t.Error("Expected greeter to be injected before Init")
+	}
+}
@@ -43,8 +43,8 @@ func TestFullIntegration(t *testing.T) {
 	service := &IntegrationTestService{}
 	app.AddService(service)
 
-	// Load services
-	app.loadAllServices()
+	// Build routes
+	app.Build()
 
 	// Test GET endpoint with service-level middleware
 	t.Run("GET endpoint with service middleware", func(t *testing.T) {
@@ -53,7 +53,7 @@ func TestFullIntegration(t *testing.T) {
 		req := httptest.NewRequest("GET", "/integration/test", nil)
 		w := httptest.NewRecorder()
 
-		app.mux.ServeHTTP(w, req)
+		app.ServeHTTP(w, req)
 
 		// Check response
 		if w.Code != http.StatusOK {
@@ -84,7 +84,7 @@ func TestFullIntegration(t *testing.T) {
 		req := httptest.NewRequest("POST", "/integration/create", nil)
 		w := httptest.NewRecorder()
 
-		app.mux.ServeHTTP(w, req)
+		app.ServeHTTP(w, req)
 
 		// Check response
 		if w.Code != http.StatusOK {
@@ -113,7 +113,7 @@ func TestFullIntegration(t *testing.T) {
 		req := httptest.NewRequest("PUT", "/integration/test", nil)
 		w := httptest.NewRecorder()
 
-		app.mux.ServeHTTP(w, req)
+		app.ServeHTTP(w, req)
 
 		if w.Code != http.StatusMethodNotAllowed {
 			t.Errorf("Expected status 405, got %d", w.Code)
@@ -125,7 +125,7 @@ func TestFullIntegration(t *testing.T) {
 		req := httptest.NewRequest("GET", "/nonexistent", nil)
 		w := httptest.NewRecorder()
 
-		app.mux.ServeHTTP(w, req)
+		app.ServeHTTP(w, req)
 
 		if w.Code != http.StatusNotFound {
 			t.Errorf("Expected status 404, got %d", w.Code)
@@ -169,13 +169,13 @@ func TestServiceLoading(t *testing.T) {
 		})
 	})
 
-	// Load services (should not panic)
-	app.loadAllServices()
+	// Build routes (should not panic)
+	app.Build()
 
 	// Test that routes were registered
 	req := httptest.NewRequest("GET", "/integration/test", nil)
 	w := httptest.NewRecorder()
-	app.mux.ServeHTTP(w, req)
+	app.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200 for loaded service, got %d", w.Code)
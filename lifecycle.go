@@ -0,0 +1,188 @@
+package neon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Initializer : Services implementing it are initialized before routes are built,
+// e.g. to open database pools; app can be used to register middlewares
+type Initializer interface {
+	Init(ctx context.Context, app *App) error
+}
+
+// Starter : Services implementing it are started before the server listens,
+// e.g. to launch background workers
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper : Services implementing it are stopped on shutdown, in reverse registration order
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// InitServices calls Init on every registered service not initialized yet, in
+// registration order, including services added by another service's Init.
+// It stops at the first failure. RunContext calls it before building routes;
+// call it yourself when serving the app through Handler.
+func (s *App) InitServices(ctx context.Context) error {
+	for {
+		service, ok := s.nextUninitialized()
+		if !ok {
+			return nil
+		}
+		// Dependencies are in place before Init runs
+		s.mu.Lock()
+		injectErrs := s.injectService(service)
+		s.mu.Unlock()
+		if len(injectErrs) > 0 {
+			return fmt.Errorf("neon: inject %T: %w", service, injectErrs)
+		}
+		if init, ok := service.(Initializer); ok {
+			if err := init.Init(ctx, s); err != nil {
+				return fmt.Errorf("neon: init %T: %w", service, err)
+			}
+		}
+		s.mu.Lock()
+		s.initialized = append(s.initialized, service)
+		s.mu.Unlock()
+	}
+}
+
+// nextUninitialized picks the first registered service InitServices has not seen
+func (s *App) nextUninitialized() (Moduler, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.services {
+		if entry.service == nil || s.seen[entry.service] {
+			continue
+		}
+		s.seen[entry.service] = true
+		return entry.service, true
+	}
+	return nil, false
+}
+
+// StartServices calls Start on every initialized service in registration order,
+// stopping at the first failure. RunContext calls it before listening.
+// Services added afterwards are initialized and started with ctx as they are
+// added, until StopServices.
+func (s *App) StartServices(ctx context.Context) error {
+	s.mu.RLock()
+	services := append([]Moduler(nil), s.initialized...)
+	s.mu.RUnlock()
+
+	for _, service := range services {
+		if starter, ok := service.(Starter); ok {
+			if err := starter.Start(ctx); err != nil {
+				return fmt.Errorf("neon: start %T: %w", service, err)
+			}
+		}
+	}
+	s.mu.Lock()
+	s.startCtx = ctx
+	s.mu.Unlock()
+	return nil
+}
+
+// startService injects, initializes and starts a service added once the
+// services are started, before it is routed. A service failing to start is
+// stopped again.
+func (s *App) startService(service Moduler) error {
+	s.mu.Lock()
+	ctx := s.startCtx
+	if ctx == nil || service == nil || s.seen[service] {
+		s.mu.Unlock()
+		return nil
+	}
+	injectErrs := s.injectService(service)
+	s.mu.Unlock()
+	if len(injectErrs) > 0 {
+		return fmt.Errorf("neon: inject %T: %w", service, injectErrs)
+	}
+
+	if init, ok := service.(Initializer); ok {
+		if err := init.Init(ctx, s); err != nil {
+			return fmt.Errorf("neon: init %T: %w", service, err)
+		}
+	}
+	if starter, ok := service.(Starter); ok {
+		if err := starter.Start(ctx); err != nil {
+			err = fmt.Errorf("neon: start %T: %w", service, err)
+			if stopper, ok := service.(Stopper); ok {
+				err = errors.Join(err, stopper.Stop(ctx))
+			}
+			return err
+		}
+	}
+	s.mu.Lock()
+	s.seen[service] = true
+	s.initialized = append(s.initialized, service)
+	s.mu.Unlock()
+	return nil
+}
+
+// stopService stops an initialized service that is no longer registered, so
+// adding it again initializes it again
+func (s *App) stopService(service Moduler) error {
+	s.mu.Lock()
+	if service == nil || s.registered(service) {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.seen, service)
+	found := false
+	for i, initialized := range s.initialized {
+		if initialized == service {
+			s.initialized = append(s.initialized[:i:i], s.initialized[i+1:]...)
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	stopper, ok := service.(Stopper)
+	if !found || !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	if err := stopper.Stop(ctx); err != nil {
+		return fmt.Errorf("neon: stop %T: %w", service, err)
+	}
+	return nil
+}
+
+// registered reports whether service is still added to the app
+// Must be called with s.mu held
+func (s *App) registered(service Moduler) bool {
+	for _, entry := range s.services {
+		if entry.service == service {
+			return true
+		}
+	}
+	return false
+}
+
+// StopServices calls Stop on every initialized service in reverse registration
+// order and returns all failures. Each service is stopped at most once;
+// Shutdown calls it once the server stopped accepting requests.
+func (s *App) StopServices(ctx context.Context) error {
+	s.mu.Lock()
+	services := s.initialized
+	s.initialized = nil
+	s.startCtx = nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(services) - 1; i >= 0; i-- {
+		if stopper, ok := services[i].(Stopper); ok {
+			if err := stopper.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("neon: stop %T: %w", services[i], err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,205 @@
+package neon
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// LifecycleService records its hook calls into a shared log
+type LifecycleService struct {
+	Module  `base:"/life"`
+	getLife Get `url:"/"`
+
+	name     string
+	log      *[]string
+	initErr  error
+	startErr error
+}
+
+func (s *LifecycleService) Init(ctx context.Context, app *App) error {
+	*s.log = append(*s.log, "init "+s.name)
+	return s.initErr
+}
+
+func (s *LifecycleService) Start(ctx context.Context) error {
+	*s.log = append(*s.log, "start "+s.name)
+	return s.startErr
+}
+
+func (s *LifecycleService) Stop(ctx context.Context) error {
+	*s.log = append(*s.log, "stop "+s.name)
+	return nil
+}
+
+func (s LifecycleService) GetLife(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("alive"))
+}
+
+// InitOnlyService registers the middleware its module needs during Init
+type InitOnlyService struct {
+	Module  `base:"/secure" middleware:"Auth"`
+	getData Get `url:"/"`
+}
+
+func (s *InitOnlyService) Init(ctx context.Context, app *App) error {
+	app.RegisterMiddleware("Auth", passThrough)
+	return nil
+}
+
+func (s InitOnlyService) GetData(w http.ResponseWriter, r *http.Request) {}
+
+func equalLog(got []string, want ...string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	var log []string
+	app := New(&Config{Port: freePort(t)})
+	app.AddService(&LifecycleService{name: "a", log: &log})
+	app.Group("/b").AddService(&LifecycleService{name: "b", log: &log})
+	app.AddService(&InitOnlyService{})
+	app.OnShutdown(func(ctx context.Context) error {
+		log = append(log, "hook")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.RunContext(ctx) }()
+
+	base := "http://127.0.0.1:" + strconv.Itoa(app.Port)
+	waitForServer(t, base+"/life/")
+
+	// Middleware registered in Init is in place when routes are built
+	resp, err := http.Get(base + "/secure/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 for route needing Init middleware, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-runErr; err != nil {
+		t.Fatalf("Expected RunContext to return nil, got %v", err)
+	}
+
+	if !equalLog(log, "init a", "init b", "start a", "start b", "stop b", "stop a", "hook") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+}
+
+func TestInitFailureAbortsStartup(t *testing.T) {
+	var log []string
+	dbErr := errors.New("db unreachable")
+	app := New(&Config{Port: freePort(t)})
+	app.AddService(&LifecycleService{name: "a", log: &log})
+	app.AddService(&LifecycleService{name: "b", log: &log, initErr: dbErr})
+	app.AddService(&LifecycleService{name: "c", log: &log})
+
+	err := app.RunContext(context.Background())
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("Expected init error, got %v", err)
+	}
+	if want := "neon: init *neon.LifecycleService: db unreachable"; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+	// Services initialized so far are stopped, nothing is started
+	if !equalLog(log, "init a", "init b", "stop a") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+}
+
+func TestStartFailureStopsServices(t *testing.T) {
+	var log []string
+	workerErr := errors.New("worker failed")
+	app := New()
+	app.AddService(&LifecycleService{name: "a", log: &log})
+	app.AddService(&LifecycleService{name: "b", log: &log, startErr: workerErr})
+
+	ctx := context.Background()
+	if err := app.InitServices(ctx); err != nil {
+		t.Fatalf("InitServices failed: %v", err)
+	}
+	// Already initialized services are not initialized again
+	if err := app.InitServices(ctx); err != nil {
+		t.Fatalf("InitServices failed: %v", err)
+	}
+	if err := app.StartServices(ctx); !errors.Is(err, workerErr) {
+		t.Fatalf("Expected start error, got %v", err)
+	}
+	app.StopServices(ctx)
+	app.StopServices(ctx) // each service is stopped once
+
+	if !equalLog(log, "init a", "init b", "start a", "start b", "stop b", "stop a") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+}
+
+func TestLifecycleOfRuntimeServices(t *testing.T) {
+	var log []string
+	app := New()
+	app.AddService(&LifecycleService{name: "a", log: &log})
+	ctx := context.Background()
+	if err := app.InitServices(ctx); err != nil {
+		t.Fatal(err)
+	}
+	app.Build()
+	if err := app.StartServices(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Added while serving: initialized and started before it is routed
+	b := &LifecycleService{name: "b", log: &log}
+	if err := app.Group("/b").AddService(b); err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/b/life/", nil)); w.Body.String() != "alive" {
+		t.Errorf("Expected 'alive', got '%s'", w.Body.String())
+	}
+	if !equalLog(log, "init a", "start a", "init b", "start b") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+
+	// Removed: stopped once and not again on shutdown
+	if err := app.RemoveService(b); err != nil {
+		t.Fatalf("RemoveService failed: %v", err)
+	}
+	if err := app.StopServices(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !equalLog(log, "init a", "start a", "init b", "start b", "stop b", "stop a") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+}
+
+func TestRuntimeServiceFailingToStart(t *testing.T) {
+	var log []string
+	app := New()
+	app.Build()
+	app.StartServices(context.Background())
+
+	startErr := errors.New("no workers")
+	if err := app.AddService(&LifecycleService{name: "c", log: &log, startErr: startErr}); !errors.Is(err, startErr) {
+		t.Fatalf("Expected start error, got %v", err)
+	}
+	if !equalLog(log, "init c", "start c", "stop c") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/life/", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected a service failing to start not to be routed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,202 @@
+package neon
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+)
+
+// Listener : An address the app serves on
+// Every listener serves the same route table and is shut down together
+type Listener struct {
+	// Network is "tcp" (default) or "unix"
+	Network string
+	// Addr is a host:port for tcp, e.g. ":8443", or a socket path for unix
+	Addr string
+
+	// TLSCert and TLSKey serve HTTPS on the listener; they must be set together
+	// and are reloaded when the files change
+	TLSCert string
+	TLSKey  string
+	// TLSConfig overrides App.TLSConfig; it serves HTTPS on its own when it
+	// carries certificates
+	TLSConfig *tls.Config
+
+	// RedirectToHTTPS answers every request with a redirect to the same URL
+	// on the first TLS listener instead of serving the app, e.g. on port 80
+	RedirectToHTTPS bool
+}
+
+func (l Listener) network() string {
+	if l.Network == "" {
+		return "tcp"
+	}
+	return l.Network
+}
+
+func (l Listener) tls() bool {
+	return l.TLSCert != "" || hasCertificates(l.TLSConfig)
+}
+
+func (l Listener) String() string {
+	scheme := "http"
+	if l.tls() {
+		scheme = "https"
+	}
+	if l.network() == "unix" {
+		scheme += "+unix"
+	}
+	return scheme + "://" + l.Addr
+}
+
+// listeners returns the configured listeners, or the single listener on Port
+func (s *App) listeners() []Listener {
+	if len(s.Listeners) > 0 {
+		return s.Listeners
+	}
+	l := Listener{
+		Addr:      fmt.Sprintf(":%d", s.Port),
+		TLSCert:   s.TLSCert,
+		TLSKey:    s.TLSKey,
+		TLSConfig: s.TLSConfig,
+	}
+	if s.devCert != nil {
+		l.TLSConfig = s.devTLSConfig()
+	}
+	return []Listener{l}
+}
+
+var errDevTLSListeners = errors.New("DevTLS serves the listener on Port and cannot be combined with Listeners")
+
+// checkListeners reports listener configurations that cannot start
+func (s *App) checkListeners() error {
+	if s.DevTLS && len(s.Listeners) > 0 {
+		// Listeners without certificates are plain HTTP on purpose, e.g. redirects
+		return fmt.Errorf("neon: %w", errDevTLSListeners)
+	}
+	for _, l := range s.listeners() {
+		if (l.TLSCert == "") != (l.TLSKey == "") {
+			return fmt.Errorf("neon: listen on %s: %w", l, errHalfTLS)
+		}
+	}
+	return nil
+}
+
+// boundListener : A listener opened by listen, with the server that will serve it
+type boundListener struct {
+	Listener
+	ln  net.Listener
+	srv *http.Server
+}
+
+// listen opens every configured listener; if one fails those already open are closed
+func (s *App) listen() ([]boundListener, error) {
+	configs := s.listeners()
+	httpsPort := ""
+	for _, l := range configs {
+		if l.tls() && l.network() == "tcp" {
+			if _, port, err := net.SplitHostPort(l.Addr); err == nil {
+				httpsPort = port
+			}
+			break
+		}
+	}
+
+	var bound []boundListener
+	for _, l := range configs {
+		var tlsConfig *tls.Config
+		if l.tls() {
+			s.mu.Lock()
+			cfg, err := s.serverTLSConfig(l)
+			s.mu.Unlock()
+			if err != nil {
+				closeAll(bound)
+				return nil, fmt.Errorf("neon: listen on %s: %w", l, err)
+			}
+			tlsConfig = cfg
+		}
+
+		ln, err := openListener(l)
+		if err != nil {
+			closeAll(bound)
+			return nil, fmt.Errorf("neon: listen on %s: %w", l, err)
+		}
+		handler := s.Handler()
+		if l.RedirectToHTTPS {
+			handler = httpsRedirect(httpsPort)
+		}
+		srv := &http.Server{Handler: handler, TLSConfig: tlsConfig}
+		bound = append(bound, boundListener{Listener: l, ln: ln, srv: srv})
+	}
+	return bound, nil
+}
+
+func closeAll(bound []boundListener) {
+	for _, b := range bound {
+		b.ln.Close()
+	}
+}
+
+func openListener(l Listener) (net.Listener, error) {
+	if l.network() == "unix" {
+		if err := removeStaleSocket(l.Addr); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen(l.network(), l.Addr)
+}
+
+var errSocketInUse = errors.New("socket is in use by another process")
+
+// removeStaleSocket removes a socket left behind by a crashed process, which
+// would make Listen fail; a socket still accepting connections is kept
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode().Type() != fs.ModeSocket {
+		return nil
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		conn.Close()
+		return errSocketInUse
+	}
+	if !errors.Is(err, syscall.ECONNREFUSED) {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// serve blocks serving b until its server is shut down or fails
+func (b boundListener) serve() error {
+	var err error
+	if b.srv.TLSConfig != nil {
+		// Certificates come from TLSConfig, see serverTLSConfig
+		err = b.srv.ServeTLS(b.ln, "", "")
+	} else {
+		err = b.srv.Serve(b.ln)
+	}
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("neon: serve %s: %w", b.Listener, err)
+	}
+	return err
+}
+
+// httpsRedirect redirects every request to the same URL over HTTPS on port,
+// the default HTTPS port when empty
+func httpsRedirect(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,222 @@
+package neon
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate for localhost and 127.0.0.1
+func writeTestCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
+
+// insecureClient trusts any certificate and does not follow redirects
+var insecureClient = &http.Client{
+	Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// getBody polls url until it answers and returns the response and body
+func getBody(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	var lastErr error
+	for i := 0; i < 100; i++ {
+		resp, err := client.Get(url)
+		if err == nil {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return resp, string(body)
+		}
+		lastErr = err
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("GET %s failed: %v", url, lastErr)
+	return nil, ""
+}
+
+func TestMultipleListeners(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+	socket := filepath.Join(dir, "app.sock")
+	httpPort, httpsPort, redirectPort := freePort(t), freePort(t), freePort(t)
+
+	app := New(&Config{Listeners: []Listener{
+		{Addr: "127.0.0.1:" + strconv.Itoa(httpPort)},
+		{Addr: "127.0.0.1:" + strconv.Itoa(httpsPort), TLSCert: certFile, TLSKey: keyFile},
+		{Network: "unix", Addr: socket},
+		{Addr: "127.0.0.1:" + strconv.Itoa(redirectPort), RedirectToHTTPS: true},
+	}})
+	app.AddService(&TestService{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.RunContext(ctx) }()
+
+	if _, body := getBody(t, insecureClient, "http://127.0.0.1:"+strconv.Itoa(httpPort)+"/test/endpoint"); body != "test response" {
+		t.Errorf("HTTP: expected 'test response', got '%s'", body)
+	}
+	if resp, body := getBody(t, insecureClient, "https://127.0.0.1:"+strconv.Itoa(httpsPort)+"/test/endpoint"); body != "test response" || resp.TLS == nil {
+		t.Errorf("HTTPS: expected 'test response' over TLS, got '%s'", body)
+	}
+
+	unixClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	if _, body := getBody(t, unixClient, "http://sidecar/test/endpoint"); body != "test response" {
+		t.Errorf("Unix socket: expected 'test response', got '%s'", body)
+	}
+
+	resp, _ := getBody(t, insecureClient, "http://localhost:"+strconv.Itoa(redirectPort)+"/test/endpoint?x=1")
+	want := "https://localhost:" + strconv.Itoa(httpsPort) + "/test/endpoint?x=1"
+	if resp.StatusCode != http.StatusPermanentRedirect || resp.Header.Get("Location") != want {
+		t.Errorf("Redirect: expected 308 to %s, got %d %s", want, resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	cancel()
+	if err := <-runErr; err != nil {
+		t.Fatalf("Expected RunContext to return nil, got %v", err)
+	}
+
+	// Every listener is closed
+	for _, port := range []int{httpPort, httpsPort, redirectPort} {
+		if conn, err := net.Dial("tcp", "127.0.0.1:"+strconv.Itoa(port)); err == nil {
+			conn.Close()
+			t.Errorf("Expected port %d to be closed after shutdown", port)
+		}
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("Expected socket file to be removed, got %v", err)
+	}
+}
+
+func TestListenFailureAbortsStartup(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+	free := freePort(t)
+
+	var log []string
+	app := New(&Config{Listeners: []Listener{
+		{Addr: "127.0.0.1:" + strconv.Itoa(free)},
+		{Addr: busy.Addr().String()},
+	}})
+	app.AddService(&LifecycleService{name: "a", log: &log})
+
+	if err := app.RunContext(context.Background()); err == nil {
+		t.Fatal("Expected error when a listener cannot be opened")
+	}
+	// The listener opened first is released again
+	if ln, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(free)); err != nil {
+		t.Errorf("Expected port %d to be released, got %v", free, err)
+	} else {
+		ln.Close()
+	}
+	if !equalLog(log, "init a", "start a", "stop a") {
+		t.Errorf("Unexpected lifecycle order %v", log)
+	}
+}
+
+func TestUnixSocketTakeover(t *testing.T) {
+	dir := t.TempDir()
+
+	// A socket left behind by a crashed process is replaced
+	stale := filepath.Join(dir, "stale.sock")
+	ln, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	if err := removeStaleSocket(stale); err != nil {
+		t.Errorf("Expected stale socket to be removed, got %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Expected stale socket file to be gone, got %v", err)
+	}
+
+	// A socket still served by another process is left alone
+	live := filepath.Join(dir, "live.sock")
+	ln, err = net.Listen("unix", live)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	app := New(&Config{Listeners: []Listener{{Network: "unix", Addr: live}}})
+	app.AddService(&TestService{})
+	if err := app.RunContext(context.Background()); !errors.Is(err, errSocketInUse) {
+		t.Fatalf("Expected RunContext to refuse a socket in use, got %v", err)
+	}
+	if conn, err := net.Dial("unix", live); err != nil {
+		t.Errorf("Expected the running listener to keep its socket, got %v", err)
+	} else {
+		conn.Close()
+	}
+}
+
+func TestHTTPSRedirectDefaultPort(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com/a?b=c", nil)
+	req.Host = "example.com:80"
+	w := &recordingWriter{header: http.Header{}}
+	httpsRedirect("").ServeHTTP(w, req)
+	if got := w.header.Get("Location"); got != "https://example.com/a?b=c" {
+		t.Errorf("Expected redirect to https://example.com/a?b=c, got %s", got)
+	}
+}
+
+type recordingWriter struct {
+	header http.Header
+	code   int
+}
+
+func (r *recordingWriter) Header() http.Header         { return r.header }
+func (r *recordingWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (r *recordingWriter) WriteHeader(code int)        { r.code = code }
@@ -0,0 +1,95 @@
+package neon
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// mountEntry : An http.Handler served under a prefix
+type mountEntry struct {
+	prefix     string
+	handler    http.Handler
+	skipGlobal bool
+}
+
+// MountOption : Configures a mounted handler
+type MountOption func(*mountEntry)
+
+// WithoutGlobalMiddlewares serves the mounted handler without the app's global middlewares
+func WithoutGlobalMiddlewares() MountOption {
+	return func(m *mountEntry) {
+		m.skipGlobal = true
+	}
+}
+
+// Mount serves handler for every request under prefix, with the prefix
+// stripped from the request path. Any http.Handler can be mounted, including
+// another *App whose routes then show up in Routes.
+// Global middlewares run before the handler unless WithoutGlobalMiddlewares is given.
+// Like AddService it is safe to call while serving.
+// Mounting an app into itself, directly or through other apps, is an error.
+func (s *App) Mount(prefix string, handler http.Handler, opts ...MountOption) error {
+	if sub, ok := handler.(*App); ok && sub.reaches(s) {
+		return fmt.Errorf("neon: mount %s: %w", prefix, errMountCycle)
+	}
+	m := mountEntry{prefix: cleanPrefix(prefix), handler: handler}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	return s.update(func() {
+		s.mounts = append(s.mounts, m)
+	})
+}
+
+var errMountCycle = errors.New("app would mount itself")
+
+// reaches reports whether target is s or mounted in s, directly or through other apps
+func (s *App) reaches(target *App) bool {
+	if s == target {
+		return true
+	}
+	s.mu.RLock()
+	var subs []*App
+	for _, m := range s.mounts {
+		if sub, ok := m.handler.(*App); ok {
+			subs = append(subs, sub)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.reaches(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// mountEndpoint converts a mount into an endpoint matching every method under its prefix
+func (s *App) mountEndpoint(m mountEntry) endpoint {
+	var mws []Middleware
+	if !m.skipGlobal {
+		mws = s.globalMiddlewares
+	}
+	handler := http.StripPrefix(m.prefix, m.handler)
+	return endpoint{
+		method:            MethodAny,
+		url:               m.prefix + "/",
+		handler:           s.wrapWithMiddlewares(handler.ServeHTTP, mws),
+		service:           fmt.Sprintf("%T", m.handler),
+		mounted:           m.handler,
+		globalMiddlewares: middlewareNames(mws),
+	}
+}
+
+// mountedRoutes expands the routes of a mounted *App under the mount prefix
+func mountedRoutes(ep endpoint, sub *App) []RouteInfo {
+	prefix := ep.url[:len(ep.url)-1]
+	routes := sub.Routes()
+	for i := range routes {
+		routes[i].Path = prefix + routes[i].Path
+		routes[i].GlobalMiddlewares = append(append([]string(nil), ep.globalMiddlewares...), routes[i].GlobalMiddlewares...)
+	}
+	return routes
+}
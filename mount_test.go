@@ -0,0 +1,149 @@
+package neon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMountHandler(t *testing.T) {
+	legacy := http.NewServeMux()
+	legacy.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("legacy " + r.Method + " " + r.URL.Path))
+	})
+
+	app := New()
+	app.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Global", "yes")
+			next.ServeHTTP(w, r)
+		})
+	})
+	app.AddService(&TestService{})
+	app.Mount("/legacy", legacy)
+
+	w := serve(app, httptest.NewRequest("DELETE", "/legacy/status", nil))
+	if w.Body.String() != "legacy DELETE /status" {
+		t.Errorf("Expected prefix to be stripped, got '%s'", w.Body.String())
+	}
+	if w.Header().Get("X-Global") != "yes" {
+		t.Error("Expected global middleware to run for mounted handler")
+	}
+
+	// Unknown paths are answered by the mounted handler
+	w = serve(app, httptest.NewRequest("GET", "/legacy/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+
+	// Services are unaffected
+	w = serve(app, httptest.NewRequest("GET", "/test/endpoint", nil))
+	if w.Body.String() != "test response" {
+		t.Errorf("Expected 'test response', got '%s'", w.Body.String())
+	}
+}
+
+func TestMountWithoutGlobalMiddlewares(t *testing.T) {
+	app := New()
+	app.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Global", "yes")
+			next.ServeHTTP(w, r)
+		})
+	})
+	app.Mount("/ui", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ui"))
+	}), WithoutGlobalMiddlewares())
+
+	w := serve(app, httptest.NewRequest("GET", "/ui/index.html", nil))
+	if w.Body.String() != "ui" {
+		t.Errorf("Expected 'ui', got '%s'", w.Body.String())
+	}
+	if w.Header().Get("X-Global") != "" {
+		t.Error("Expected global middleware to be skipped")
+	}
+}
+
+func TestMountSubApp(t *testing.T) {
+	admin := New()
+	admin.AddService(&TestService{})
+
+	app := New()
+	app.AddMiddleware(passThrough)
+	app.Mount("/admin", admin)
+
+	w := serve(app, httptest.NewRequest("GET", "/admin/test/endpoint", nil))
+	if w.Body.String() != "test response" {
+		t.Errorf("Expected 'test response', got '%s'", w.Body.String())
+	}
+
+	routes := app.Routes()
+	expected := []RouteInfo{{
+		Method:            "GET",
+		Path:              "/admin/test/endpoint",
+		Version:           "1",
+		Service:           "neon.TestService",
+		Handler:           "GetTest",
+		GlobalMiddlewares: []string{"neon.passThrough"},
+	}}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, routes)
+	}
+}
+
+func TestMountConflict(t *testing.T) {
+	app := New()
+	app.Mount("/test", http.NotFoundHandler())
+	app.Mount("/test/", http.NotFoundHandler())
+
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 conflict, got %v", errs)
+	}
+}
+
+func TestMountedHandlerRouteInfo(t *testing.T) {
+	app := New()
+	app.Mount("/files", http.FileServer(http.Dir(".")))
+
+	routes := app.Routes()
+	if len(routes) != 1 || routes[0].Method != "*" || routes[0].Path != "/files/" || routes[0].Service != "*http.fileHandler" {
+		t.Errorf("Unexpected routes %+v", routes)
+	}
+}
+
+func TestMountAfterBuild(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+	app.Build()
+
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("legacy " + r.URL.Path))
+	})
+	if err := app.Mount("/legacy", legacy); err != nil {
+		t.Fatalf("Mount failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/legacy/x", nil)); w.Body.String() != "legacy /x" {
+		t.Errorf("Expected mount to be served after Build, got %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestMountCycle(t *testing.T) {
+	app := New()
+	if err := app.Mount("/self", app); !errors.Is(err, errMountCycle) {
+		t.Errorf("Expected mounting an app into itself to fail, got %v", err)
+	}
+
+	a, b := New(), New()
+	if err := a.Mount("/b", b); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Mount("/a", a); !errors.Is(err, errMountCycle) {
+		t.Errorf("Expected a mount cycle to fail, got %v", err)
+	}
+	if err := a.Build(); err != nil {
+		t.Errorf("Build failed: %v", err)
+	}
+}
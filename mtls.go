@@ -0,0 +1,145 @@
+package neon
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// mTLS tag values for Module and endpoint fields
+const (
+	// MTLSRequired rejects requests without a verified client certificate
+	MTLSRequired = "required"
+	// MTLSOptional lifts a Module level requirement for one endpoint
+	MTLSOptional = "optional"
+)
+
+// ClientIdentity : The verified client certificate of a request
+type ClientIdentity struct {
+	Subject pkix.Name
+
+	// Subject alternative names
+	DNSNames       []string
+	EmailAddresses []string
+	IPAddresses    []net.IP
+	URIs           []*url.URL
+
+	// SPIFFEID is the spiffe:// URI SAN of the certificate, empty if none
+	SPIFFEID string
+
+	Certificate *x509.Certificate
+}
+
+type clientIdentityKey struct{}
+
+// ClientIdentityFrom returns the identity of the verified client certificate of
+// the request ctx belongs to. It is available to every middleware and handler
+// of requests made over mutual TLS.
+func ClientIdentityFrom(ctx context.Context) (*ClientIdentity, bool) {
+	id, ok := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return id, ok
+}
+
+// newClientIdentity parses the leaf certificate of a verified chain
+func newClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	id := &ClientIdentity{
+		Subject:        cert.Subject,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		IPAddresses:    cert.IPAddresses,
+		URIs:           cert.URIs,
+		Certificate:    cert,
+	}
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			id.SPIFFEID = uri.String()
+			break
+		}
+	}
+	return id
+}
+
+// withClientIdentity adds the identity of a verified client certificate to the
+// request context; requests without one are returned unchanged
+func withClientIdentity(r *http.Request) *http.Request {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return r
+	}
+	id := newClientIdentity(r.TLS.VerifiedChains[0][0])
+	return r.WithContext(context.WithValue(r.Context(), clientIdentityKey{}, id))
+}
+
+// RequireClientCert is a middleware rejecting requests without a verified
+// client certificate with 403; `mtls:"required"` adds it to services and endpoints
+func RequireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := ClientIdentityFrom(r.Context()); !ok {
+			http.Error(w, "Client certificate required", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requiresMTLS resolves the mtls tags of a module and one of its endpoints
+func requiresMTLS(moduleTag, endpointTag string) (bool, error) {
+	tag := endpointTag
+	if tag == "" {
+		tag = moduleTag
+	}
+	switch tag {
+	case "", MTLSOptional:
+		return false, nil
+	case MTLSRequired:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown mtls value %q, want %q or %q", tag, MTLSRequired, MTLSOptional)
+	}
+}
+
+var errNoClientCA = errors.New("mtls required but no client CA is configured, see ClientCAFile")
+
+// verifiesClientCerts reports whether any listener can verify client certificates
+func (s *App) verifiesClientCerts() bool {
+	if s.ClientCAFile != "" || verifiesClients(s.TLSConfig) {
+		return true
+	}
+	for _, l := range s.Listeners {
+		if verifiesClients(l.TLSConfig) {
+			return true
+		}
+	}
+	return false
+}
+
+func verifiesClients(cfg *tls.Config) bool {
+	return cfg != nil && (cfg.ClientCAs != nil || cfg.GetConfigForClient != nil)
+}
+
+// applyClientCA makes cfg verify client certificates against the CAs in
+// ClientCAFile, keeping a stricter ClientAuth already set
+func (s *App) applyClientCA(cfg *tls.Config) error {
+	if s.ClientCAFile == "" {
+		return nil
+	}
+	pem, err := os.ReadFile(s.ClientCAFile)
+	if err != nil {
+		return fmt.Errorf("neon: load client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("neon: load client CA: no certificates in %s", s.ClientCAFile)
+	}
+	cfg.ClientCAs = pool
+	if cfg.ClientAuth < tls.VerifyClientCertIfGiven {
+		cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return nil
+}
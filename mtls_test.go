@@ -0,0 +1,204 @@
+package neon
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testCA issues client certificates for mTLS tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) writePEM(t *testing.T, dir string) string {
+	t.Helper()
+	file := filepath.Join(dir, "ca.pem")
+	os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600)
+	return file
+}
+
+// clientCert issues a client certificate for cn with a SPIFFE ID URI SAN
+func (ca *testCA) clientCert(t *testing.T, cn, spiffeID string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, _ := url.Parse(spiffeID)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn, Organization: []string{"Acme"}},
+		DNSNames:     []string{cn + ".internal"},
+		URIs:         []*url.URL{uri},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+type MTLSService struct {
+	Module  `base:"/internal" mtls:"required"`
+	whoami  Get `url:"/whoami"`
+	healthz Get `url:"/healthz" mtls:"optional"`
+}
+
+func (s MTLSService) Whoami(w http.ResponseWriter, r *http.Request) {
+	id, _ := ClientIdentityFrom(r.Context())
+	w.Write([]byte(id.Subject.CommonName + " " + id.SPIFFEID))
+}
+
+func (s MTLSService) Healthz(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("ok"))
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+	ca := newTestCA(t)
+
+	app := New(&Config{Port: freePort(t), TLSCert: certFile, TLSKey: keyFile, ClientCAFile: ca.writePEM(t, dir)})
+	app.AddService(&MTLSService{})
+	seen := make(chan string, 10)
+	app.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Global middleware sees the identity too
+			if id, ok := ClientIdentityFrom(r.Context()); ok {
+				seen <- id.Subject.CommonName
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	runApp(t, app)
+	base := "https://127.0.0.1:" + strconv.Itoa(app.Port) + "/internal"
+
+	withCert := freshTLSClient(&tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{ca.clientCert(t, "billing", "spiffe://acme.org/ns/prod/sa/billing")},
+	})
+	resp, body := getBody(t, withCert, base+"/whoami")
+	if resp.StatusCode != http.StatusOK || body != "billing spiffe://acme.org/ns/prod/sa/billing" {
+		t.Errorf("Expected identity of billing, got %d '%s'", resp.StatusCode, body)
+	}
+	if cn := <-seen; cn != "billing" {
+		t.Errorf("Expected global middleware to see billing, got %s", cn)
+	}
+
+	anonymous := freshTLSClient(&tls.Config{InsecureSkipVerify: true})
+	if resp, _ := getBody(t, anonymous, base+"/whoami"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 without client certificate, got %d", resp.StatusCode)
+	}
+	if resp, _ := getBody(t, anonymous, base+"/healthz"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected optional endpoint to serve without client certificate, got %d", resp.StatusCode)
+	}
+
+	// Certificates from an unknown CA fail the handshake
+	stranger := freshTLSClient(&tls.Config{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{newTestCA(t).clientCert(t, "mallory", "spiffe://evil.org/x")},
+	})
+	if resp, err := stranger.Get(base + "/healthz"); err == nil {
+		resp.Body.Close()
+		t.Error("Expected a certificate from an unknown CA to be rejected")
+	}
+}
+
+func TestMTLSRoutesAndErrors(t *testing.T) {
+	app := New(&Config{ClientCAFile: "ca.pem"})
+	app.AddService(&MTLSService{})
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	for _, rt := range app.Routes() {
+		if want := rt.Path == "/internal/whoami"; rt.MTLS != want {
+			t.Errorf("%s: expected MTLS %v, got %v", rt.Path, want, rt.MTLS)
+		}
+	}
+
+	// Without TLS the requirement still holds
+	if w := serve(app, httptest.NewRequest("GET", "/internal/whoami", nil)); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 over plain HTTP, got %d", w.Code)
+	}
+
+	app = New()
+	app.AddServiceSpec(ServiceSpec{Name: "spec", Endpoints: []EndpointSpec{{
+		Method: http.MethodGet, Field: "get", Handler: health, URL: "/x", MTLS: "always",
+	}}})
+	errs := conflictErrors(t, app.Build())
+	if len(errs) != 1 || errs[0].Tag != `mtls:"always"` {
+		t.Errorf("Expected error for unknown mtls value, got %v", errs)
+	}
+
+	// Nothing could verify a client certificate, so every request would get 403
+	app = New()
+	app.AddService(&MTLSService{})
+	errs = conflictErrors(t, app.Build())
+	if len(errs) != 1 || errs[0].Field != "whoami" || errs[0].Reason != errNoClientCA.Error() {
+		t.Errorf("Expected missing client CA error, got %v", errs)
+	}
+
+	// A sub-app is served through the listeners of the app it is mounted in
+	sub := New()
+	sub.AddService(&MTLSService{})
+	app = New(&Config{TLSConfig: &tls.Config{ClientCAs: x509.NewCertPool()}})
+	app.Mount("/sub", sub)
+	if err := app.Build(); err != nil {
+		t.Errorf("Expected the parent's client CA to cover the sub-app, got %v", err)
+	}
+}
+
+func TestClientIdentityFromContext(t *testing.T) {
+	if _, ok := ClientIdentityFrom(context.Background()); ok {
+		t.Error("Expected no identity in an empty context")
+	}
+	cert := newTestCA(t).clientCert(t, "api", "spiffe://acme.org/api")
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	id := newClientIdentity(leaf)
+	if id.SPIFFEID != "spiffe://acme.org/api" || id.DNSNames[0] != "api.internal" || id.Subject.Organization[0] != "Acme" {
+		t.Errorf("Unexpected identity %+v", id)
+	}
+}
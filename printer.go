@@ -38,3 +38,12 @@ func printInfo(app *App) {
 	app.Logger.Info("Version", "version", ver)
 	app.Logger.Info("Environment", "env", app.Env.String())
 }
+
+// printRoutes lists the served routes once at startup; rebuilds stay quiet
+func printRoutes(app *App) {
+	for _, rt := range app.Routes() {
+		// Count total middlewares for this route
+		total := len(rt.GlobalMiddlewares) + len(rt.GroupMiddlewares) + len(rt.ModuleMiddlewares) + len(rt.EndpointMiddlewares)
+		fmt.Printf("%s\t%s\t(%d middlewares)\n", blue(rt.Method), yellow(rt.Path), total)
+	}
+}
@@ -0,0 +1,109 @@
+package neon
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// RouteInfo : Describes a single route in the live route table
+type RouteInfo struct {
+	Method  string
+	Host    string // host template, empty when the route serves any host
+	Path    string
+	Version string
+
+	// Constraints maps typed wildcard names to their constraint, e.g. "id": "int"
+	Constraints map[string]string
+
+	// Service is the type of the service struct, e.g. "main.UserService",
+	// empty for routes added with App.Handle
+	Service string
+	// Handler is the name of the service method serving the route
+	Handler string
+
+	// MTLS is set when the route requires a verified client certificate
+	MTLS bool
+
+	// Middleware names in execution order for each level
+	GlobalMiddlewares   []string
+	GroupMiddlewares    []string
+	ModuleMiddlewares   []string
+	EndpointMiddlewares []string
+}
+
+// Routes returns the route table sorted by path and method,
+// building the app first if needed
+func (s *App) Routes() []RouteInfo {
+	t := s.table()
+	out := make([]RouteInfo, 0, len(t.endpoints))
+	for _, ep := range t.endpoints {
+		if sub, ok := ep.mounted.(*App); ok {
+			out = append(out, mountedRoutes(ep, sub)...)
+			continue
+		}
+		out = append(out, RouteInfo{
+			Method:              ep.method,
+			Host:                hostTemplate(ep.host),
+			Path:                declaredPath(ep.url),
+			Version:             ep.version,
+			Constraints:         constraintSpecs(ep.constraints),
+			Service:             ep.service,
+			Handler:             ep.handlerName,
+			MTLS:                ep.mtls,
+			GlobalMiddlewares:   ep.globalMiddlewares,
+			GroupMiddlewares:    ep.groupMiddlewares,
+			ModuleMiddlewares:   ep.moduleMiddlewares,
+			EndpointMiddlewares: ep.endpointMiddlewares,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// middlewareNames derives readable names for anonymous middlewares
+// from their function symbols, e.g. "neon.requestLogger"
+func middlewareNames(mws []Middleware) []string {
+	var names []string
+	for _, mw := range mws {
+		names = append(names, funcName(mw))
+	}
+	return names
+}
+
+func funcName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "unknown"
+	}
+	name := f.Name()
+	// Drop the package path but keep the package name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+func constraintSpecs(constraints []paramConstraint) map[string]string {
+	if len(constraints) == 0 {
+		return nil
+	}
+	specs := make(map[string]string, len(constraints))
+	for _, c := range constraints {
+		specs[c.name] = c.spec
+	}
+	return specs
+}
+
+func hostTemplate(h *hostPattern) string {
+	if h == nil {
+		return ""
+	}
+	return h.template
+}
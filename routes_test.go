@@ -0,0 +1,55 @@
+package neon
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func passThrough(next http.Handler) http.Handler {
+	return next
+}
+
+func TestRoutes(t *testing.T) {
+	app := New()
+	app.AddMiddleware(passThrough)
+	app.RegisterMiddleware("Auth", passThrough)
+	app.RegisterMiddleware("RateLimit", passThrough)
+	app.AddService(&IntegrationTestService{})
+
+	routes := app.Routes()
+
+	expected := []RouteInfo{
+		{
+			Method:              "POST",
+			Path:                "/integration/create",
+			Version:             "1",
+			Service:             "neon.IntegrationTestService",
+			Handler:             "CreateTest",
+			GlobalMiddlewares:   []string{"neon.passThrough"},
+			ModuleMiddlewares:   []string{"Auth"},
+			EndpointMiddlewares: []string{"RateLimit"},
+		},
+		{
+			Method:            "GET",
+			Path:              "/integration/test",
+			Version:           "1",
+			Service:           "neon.IntegrationTestService",
+			Handler:           "GetTest",
+			GlobalMiddlewares: []string{"neon.passThrough"},
+			ModuleMiddlewares: []string{"Auth"},
+		},
+	}
+
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("Expected routes %+v, got %+v", expected, routes)
+	}
+}
+
+func TestRoutesEmptyApp(t *testing.T) {
+	app := New()
+
+	if routes := app.Routes(); len(routes) != 0 {
+		t.Errorf("Expected no routes, got %v", routes)
+	}
+}
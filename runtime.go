@@ -0,0 +1,87 @@
+package neon
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RemoveService removes every registration of servicePtr, from the app and
+// its groups. Like AddService it is safe to call while serving; an
+// initialized service is stopped once it no longer receives requests.
+func (s *App) RemoveService(servicePtr Moduler) error {
+	found := false
+	err := s.update(func() {
+		kept := s.services[:0:0]
+		for _, entry := range s.services {
+			if entry.service == servicePtr {
+				found = true
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		s.services = kept
+	})
+	if !found {
+		return fmt.Errorf("neon: service %T is not registered", servicePtr)
+	}
+	return errors.Join(err, s.stopService(servicePtr))
+}
+
+// DisableRoute stops serving the endpoint registered for method and path,
+// where path is written as declared and listed by Routes, e.g. "/users/" or
+// "/users/{id}". Requests then get the response they would get had the route
+// never been declared. It is safe to call while serving.
+func (s *App) DisableRoute(method, path string) error {
+	key := routeID(method, path)
+	if s.current.Load() != nil && !s.hasRoute(key) {
+		return fmt.Errorf("neon: no route %s", key)
+	}
+	return s.update(func() {
+		s.disabled[key] = true
+	})
+}
+
+// EnableRoute serves a route turned off by DisableRoute again
+func (s *App) EnableRoute(method, path string) error {
+	return s.update(func() {
+		delete(s.disabled, routeID(method, path))
+	})
+}
+
+// routeID identifies a route by method and declared path; the {$} anchor
+// added to paths ending in "/" is dropped
+func routeID(method, path string) string {
+	return strings.ToUpper(method) + " " + declaredPath(path)
+}
+
+// hasRoute reports whether the current table serves the route identified by key
+func (s *App) hasRoute(key string) bool {
+	for _, rt := range s.Routes() {
+		if routeID(rt.Method, rt.Path) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutDisabled drops endpoints serving any route turned off by DisableRoute
+func (s *App) withoutDisabled(t *routeTable, eps []endpoint) []endpoint {
+	if len(s.disabled) == 0 {
+		return eps
+	}
+	kept := eps[:0:0]
+	for _, ep := range eps {
+		disabled := false
+		for _, path := range t.config.versionedPaths(ep) {
+			if s.disabled[routeID(ep.method, path)] {
+				disabled = true
+				break
+			}
+		}
+		if !disabled {
+			kept = append(kept, ep)
+		}
+	}
+	return kept
+}
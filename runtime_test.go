@@ -0,0 +1,194 @@
+package neon
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestRuntimeServiceUpdates(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if w := serve(app, httptest.NewRequest("GET", "/integration/test", nil)); w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404 before AddService, got %d", w.Code)
+	}
+
+	app.RegisterMiddleware("Auth", passThrough)
+	app.RegisterMiddleware("RateLimit", passThrough)
+	service := &IntegrationTestService{}
+	if err := app.AddService(service); err != nil {
+		t.Fatalf("AddService failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/integration/test", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 after AddService, got %d", w.Code)
+	}
+
+	if err := app.RemoveService(service); err != nil {
+		t.Fatalf("RemoveService failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/integration/test", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after RemoveService, got %d", w.Code)
+	}
+
+	if err := app.RemoveService(service); err == nil {
+		t.Error("Expected error removing a service that is not registered")
+	}
+}
+
+func TestDisableRoute(t *testing.T) {
+	app := New()
+	app.AddService(&MethodsService{})
+	app.Build()
+
+	// Routes and DisableRoute use the path as declared in the url tag
+	if got := app.Routes()[0].Path; got != "/items/" {
+		t.Errorf("Expected declared path '/items/', got '%s'", got)
+	}
+	if err := app.DisableRoute("post", "/items/"); err != nil {
+		t.Fatalf("DisableRoute failed: %v", err)
+	}
+
+	w := serve(app, httptest.NewRequest("POST", "/items/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for disabled method, got %d", w.Code)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/items/", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected other methods to keep working, got %d", w.Code)
+	}
+
+	if err := app.DisableRoute("GET", "/nope"); err == nil {
+		t.Error("Expected error disabling an unknown route")
+	}
+
+	if err := app.EnableRoute("POST", "/items/"); err != nil {
+		t.Fatalf("EnableRoute failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("POST", "/items/", nil)); w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201 after EnableRoute, got %d", w.Code)
+	}
+}
+
+func TestStrictRuntimeUpdateKeepsTable(t *testing.T) {
+	app := New()
+	app.Strict = true
+	app.AddService(&TestService{})
+	app.Build()
+
+	if err := app.AddService(&MissingMiddlewareService{}); err == nil {
+		t.Fatal("Expected AddService to report the missing middleware")
+	}
+	// The previous table is still served
+	if w := serve(app, httptest.NewRequest("GET", "/mw/open", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 from previous table, got %d", w.Code)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/test/endpoint", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 from previous table, got %d", w.Code)
+	}
+}
+
+func TestStrictRejectedUpdateIsRolledBack(t *testing.T) {
+	app := New()
+	app.Strict = true
+	app.Build()
+
+	if err := app.AddService(&MissingMiddlewareService{}); err == nil {
+		t.Fatal("Expected AddService to report the missing middleware")
+	}
+	// The rejected service does not poison later updates
+	if err := app.AddService(&TestService{}); err != nil {
+		t.Fatalf("Expected AddService to succeed, got %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/test/endpoint", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 after AddService, got %d", w.Code)
+	}
+	if err := app.Build(); err != nil {
+		t.Errorf("Expected the rejected service to be gone, got %v", err)
+	}
+}
+
+func TestUpdateReturnsOnlyItsOwnErrors(t *testing.T) {
+	app := New()
+	app.Build()
+
+	if err := app.AddService(TestService{}); err == nil {
+		t.Fatal("Expected AddService to reject a non-pointer service")
+	}
+	if err := app.AddService(&MissingMiddlewareService{}); err == nil {
+		t.Fatal("Expected AddService to report the missing middleware")
+	}
+	if err := app.AddService(&TestService{}); err != nil {
+		t.Errorf("Expected AddService to succeed, got %v", err)
+	}
+	if err := app.Provide(42); err != nil {
+		t.Errorf("Expected Provide to succeed, got %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/mw/open", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected valid endpoints of a partly broken service to be served, got %d", w.Code)
+	}
+}
+
+func TestRebuildsDoNotPrint(t *testing.T) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	app := New()
+	app.AddService(&TestService{})
+	app.Build()
+	app.AddService(&SlowService{})
+	app.DisableRoute("GET", "/slow/")
+	os.Stdout = stdout
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	if len(out) != 0 {
+		t.Errorf("Expected builds to print nothing, got %q", out)
+	}
+}
+
+// Run with -race: requests must never observe a table being modified
+func TestConcurrentUpdatesAndRequests(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+	app.Build()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				w := serve(app, httptest.NewRequest("GET", "/test/endpoint", nil))
+				if w.Code != http.StatusOK {
+					t.Errorf("Expected status 200 during updates, got %d", w.Code)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		service := &SlowService{}
+		app.AddService(service)
+		app.DisableRoute("GET", "/slow/")
+		app.EnableRoute("GET", "/slow/")
+		app.RemoveService(service)
+	}
+	close(stop)
+	wg.Wait()
+}
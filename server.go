@@ -1,11 +1,20 @@
 package neon
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/go-logr/logr"
 )
@@ -33,18 +42,83 @@ const (
 )
 
 type App struct {
-	Env     Env
-	Port    int
-	TLSCert string
-	TLSKey  string
+	Env  Env
+	Port int
+	// TLSCert and TLSKey serve HTTPS whenever both are set; setting only one
+	// is a startup error. The files are reloaded when they change on disk,
+	// checked every CertReloadInterval, and on SIGHUP.
+	TLSCert            string
+	TLSKey             string
+	CertReloadInterval time.Duration
+	// TLSConfig overrides the TLS settings of HTTPS listeners; it serves
+	// HTTPS on its own when it carries certificates
+	TLSConfig *tls.Config
+	// ClientCAFile holds PEM CA certificates verifying client certificates
+	// presented to HTTPS listeners, see `mtls:"required"`
+	ClientCAFile string
+
+	// DevTLS serves HTTPS on Port in DevEnv with a generated self-signed
+	// certificate for localhost when no certificate is configured; it cannot
+	// be combined with Listeners. DevCertDir, when set, receives the CA,
+	// certificate and key for curl or browser trust
+	DevTLS     bool
+	DevCertDir string
+
+	// Listeners replaces the single listener on Port when set
+	Listeners []Listener
+
+	// ShutdownTimeout bounds how long RunContext waits for in-flight
+	// requests to finish once it starts shutting down
+	ShutdownTimeout time.Duration
+
+	// Versioning selects how `v` tags are exposed to clients
+	Versioning VersionStrategy
+	// DefaultVersion serves requests that do not name a version
+	DefaultVersion string
+	// VersionHeader is read when Versioning is HeaderVersioning
+	VersionHeader string
+
+	// Strict refuses to start the server when any service or endpoint
+	// could not be registered, instead of serving the remaining routes
+	Strict bool
+
+	// NotFound and MethodNotAllowed replace the default plain-text 404 and 405
+	// responses. Both run through global middleware; for 405 the Allow header
+	// is already set when the handler is called.
+	NotFound         http.Handler
+	MethodNotAllowed http.Handler
+
+	// TrailingSlash selects how /users/ is treated for a /users route and vice versa
+	TrailingSlash TrailingSlashPolicy
 
 	Logger logr.Logger
 
-	mux               *http.ServeMux
-	services          []Moduler
+	// mu guards the registrations below and serializes builds
+	mu                sync.RWMutex
+	services          []serviceEntry
 	middleware        map[string]Middleware
+	constraints       map[string]ConstraintFactory
 	globalMiddlewares []Middleware
-	routes            map[string]map[string]http.HandlerFunc // path -> method -> handler
+	addErrs           RegistrationErrors // services rejected by AddService before the first build
+	buildErrs         RegistrationErrors // problems of the current table
+	mounts            []mountEntry
+	disabled          map[string]bool  // "METHOD path" of routes turned off by DisableRoute
+	seen              map[Moduler]bool // services picked up by InitServices
+	initialized       []Moduler        // services to stop on shutdown, in registration order
+	startCtx          context.Context  // set once services are started; later services start on add
+	providers         []*provider
+	injected          map[Moduler]bool // services whose inject fields are set
+
+	current atomic.Pointer[routeTable] // nil until Build
+
+	servers       []*http.Server
+	certs         []*certReloader
+	devCert       *DevCert
+	shutdownHooks []func(context.Context) error
+	starting      chan struct{} // closed once RunContext published its servers or gave up
+	stopping      bool          // Shutdown was called
+	shutdownDone  chan struct{} // closed once Shutdown finished
+	shutdownOnce  sync.Once
 }
 
 func (s *App) SetEnv(e Env) {
@@ -53,21 +127,39 @@ func (s *App) SetEnv(e Env) {
 
 // Config :
 type Config struct {
-	Port    int
-	TLSCert string
-	TLSKey  string
+	Port      int
+	TLSCert   string
+	TLSKey    string
+	TLSConfig *tls.Config
+
+	ClientCAFile string
+
+	DevTLS     bool
+	DevCertDir string
+
+	// Listeners replaces the single listener on Port when set
+	Listeners []Listener
+
+	ShutdownTimeout time.Duration
 }
 
+const defaultShutdownTimeout = 30 * time.Second
+
 type Middleware func(http.Handler) http.Handler
 
 // New : Create a New Server
 func New(conf ...*Config) *App {
 	app := new(App)
 	app.middleware = make(map[string]Middleware)
-	app.mux = http.NewServeMux()
+	app.constraints = make(map[string]ConstraintFactory)
 	app.globalMiddlewares = make([]Middleware, 0)
-	app.routes = make(map[string]map[string]http.HandlerFunc)
+	app.disabled = make(map[string]bool)
+	app.seen = make(map[Moduler]bool)
+	app.injected = make(map[Moduler]bool)
 	app.Logger = logr.Discard() // Initialize with no-op logger by default
+	app.ShutdownTimeout = defaultShutdownTimeout
+	app.DefaultVersion = defaultVersion
+	app.VersionHeader = defaultVersionHeader
 	if len(conf) > 0 {
 		if conf[0].Port != 0 {
 			app.Port = conf[0].Port
@@ -76,6 +168,14 @@ func New(conf ...*Config) *App {
 		}
 		app.TLSCert = conf[0].TLSCert
 		app.TLSKey = conf[0].TLSKey
+		app.TLSConfig = conf[0].TLSConfig
+		app.ClientCAFile = conf[0].ClientCAFile
+		app.DevTLS = conf[0].DevTLS
+		app.DevCertDir = conf[0].DevCertDir
+		app.Listeners = conf[0].Listeners
+		if conf[0].ShutdownTimeout > 0 {
+			app.ShutdownTimeout = conf[0].ShutdownTimeout
+		}
 	} else {
 		app.Port = 8080
 	}
@@ -88,143 +188,307 @@ func (s *App) SetLogger(logger logr.Logger) {
 }
 
 // Add a middleware for services
+// It takes effect on the next Build
 func (s *App) AddMiddleware(fun Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.globalMiddlewares = append(s.globalMiddlewares, fun)
 }
 
 func (s *App) RegisterMiddleware(name string, fn Middleware) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.middleware[name] = fn
 }
 
 // AddService : Add Service to app
 // Service must embed neon.Module
-func (s *App) AddService(servicePtr Moduler) {
+// Before the first Build the returned error is also reported again by Build.
+// Once the app is built the route table is rebuilt, so services can be added
+// while serving; only the errors the service caused are returned, see update
+func (s *App) AddService(servicePtr Moduler) error {
+	return s.addService(servicePtr, nil)
+}
+
+// serviceEntry : A service added to the app and the group it was added through
+type serviceEntry struct {
+	service Moduler
+	spec    *ServiceSpec // nil for services described by their struct tags
+	group   *Group       // nil when added to the app directly
+}
+
+func (s *App) addService(servicePtr Moduler, group *Group) error {
+	if err := validateService(servicePtr); err != nil {
+		return s.reject(err)
+	}
+	return s.addEntry(serviceEntry{service: servicePtr, group: group})
+}
+
+// addEntry routes a service, running its lifecycle first once the app's
+// services are started; a service whose routes are rejected is stopped again
+func (s *App) addEntry(entry serviceEntry) error {
+	if err := s.startService(entry.service); err != nil {
+		return err
+	}
+	err := s.update(func() {
+		s.services = append(s.services, entry)
+	})
+	if err != nil {
+		err = errors.Join(err, s.stopService(entry.service))
+	}
+	return err
+}
+
+// reject reports a service that could not be added at all
+func (s *App) reject(err *RegistrationError) error {
+	s.Logger.Error(nil, err.Reason, "service", err.Service)
+	s.mu.Lock()
+	if s.current.Load() == nil {
+		// Strict startup refuses to serve without this service
+		s.addErrs = append(s.addErrs, err)
+	}
+	s.mu.Unlock()
+	return RegistrationErrors{err}
+}
+
+// validateService checks that servicePtr is a pointer to a struct embedding Module
+func validateService(servicePtr Moduler) *RegistrationError {
 	serviceTypeOf := reflect.TypeOf(servicePtr)
 	if serviceTypeOf.Kind() != reflect.Ptr {
-		s.Logger.Error(nil, "All services should be passed as a pointer")
-		return
+		return &RegistrationError{Service: serviceTypeOf.String(), Reason: "All services should be passed as a pointer"}
 	}
 
 	serviceTypeOf = serviceTypeOf.Elem()
 	if serviceTypeOf.Kind() != reflect.Struct {
-		s.Logger.Error(nil, "service should be a struct")
-		return
+		return &RegistrationError{Service: serviceTypeOf.String(), Reason: "service should be a struct"}
 	}
 
 	module := reflect.TypeOf(Module{})
 	field, ok := serviceTypeOf.FieldByName(module.Name())
 	if !ok || !field.Anonymous || !field.Type.ConvertibleTo(module) {
-		s.Logger.Error(nil, "service struct must add Module Anonymously")
-		return
+		return &RegistrationError{Service: serviceTypeOf.String(), Reason: "service struct must add Module Anonymously"}
 	}
-
-	s.services = append(s.services, servicePtr)
+	return nil
 }
 
-// loadAllServices : Builds Routes for all input service structures
-func (s *App) loadAllServices() {
-	for _, service := range s.services {
-
-		// Reflect Service Data
-		module := reflect.TypeOf(Module{})
-		serviceType := reflect.TypeOf(service)
-		if serviceType.Kind() == reflect.Ptr {
-			serviceType = serviceType.Elem()
+// resolveMiddlewares looks up a comma separated list of registered middleware names,
+// returning the middlewares found, their names and the names that are not registered
+func (s *App) resolveMiddlewares(tag string) (mws []Middleware, names []string, missing []string) {
+	if len(tag) == 0 {
+		return
+	}
+	for _, middlewareName := range strings.Split(tag, ",") {
+		name := strings.Trim(middlewareName, " ")
+		mw, ok := s.middleware[name]
+		if !ok {
+			s.Logger.Error(nil, "Middleware not registered", "name", name)
+			missing = append(missing, name)
+			continue
 		}
+		mws = append(mws, mw)
+		names = append(names, name)
+	}
+	return
+}
 
-		serviceValue := reflect.ValueOf(service)
-		// Get Module level Configurations
-		field, _ := serviceType.FieldByName(module.Name())
-
-		baseURL := field.Tag.Get("base")
-		if baseURL == "" {
-			baseURL = "/"
+// loadAllServices : Builds Routes for all input service structures into t
+// Endpoints with problems are skipped and reported in the returned RegistrationErrors
+func (s *App) loadAllServices(t *routeTable) RegistrationErrors {
+	errs := append(RegistrationErrors{}, s.addErrs...)
+	var eps []endpoint
+	for _, entry := range s.services {
+		// A service with unresolved dependencies is skipped rather than served with nil fields
+		if entry.service != nil {
+			if injectErrs := s.injectService(entry.service); len(injectErrs) > 0 {
+				errs = append(errs, injectErrs...)
+				continue
+			}
 		}
+		spec := entry.spec
+		if spec == nil {
+			spec = reflectServiceSpec(entry.service)
+		}
+		serviceEps, serviceErrs := s.serviceEndpoints(spec, entry.group)
+		eps = append(eps, serviceEps...)
+		errs = append(errs, serviceErrs...)
+	}
 
-		version := field.Tag.Get("v")
-
-		// These middlewares run for specified modules only
-		moduleMiddleware := field.Tag.Get("middleware")
-
-		// Get module-level middlewares
-		var moduleMiddlewares []Middleware
-		if len(moduleMiddleware) > 0 {
-			middlewareNames := strings.Split(moduleMiddleware, ",")
-			for _, middlewareName := range middlewareNames {
-				name := strings.Trim(middlewareName, " ")
-				mw, ok := s.middleware[name]
-				if !ok {
-					s.Logger.Error(nil, "Middleware not registered", "name", name)
+	for _, m := range s.mounts {
+		if sub, ok := m.handler.(*App); ok {
+			var subErrs RegistrationErrors
+			errors.As(sub.Build(), &subErrs)
+			for _, err := range subErrs {
+				// Requests reach the sub-app through this app's listeners
+				if err.Reason == errNoClientCA.Error() && s.verifiesClientCerts() {
 					continue
 				}
-				moduleMiddlewares = append(moduleMiddlewares, mw)
+				errs = append(errs, err)
 			}
 		}
+		eps = append(eps, s.mountEndpoint(m))
+	}
 
-		for i := 0; i < serviceType.NumField(); i++ {
-			fieldType := serviceType.FieldByIndex([]int{i})
-			switch fieldType.Type.String() {
-			case "neon.Get", "neon.Put", "neon.Post", "neon.Patch", "neon.Delete", "neon.Options":
-			default:
-				continue
-			}
+	eps = s.withoutDisabled(t, eps)
 
-			apiURL := fieldType.Tag.Get("url")
-			if apiURL == "" {
-				apiURL = "/"
-			}
+	// Check the complete route set before touching the mux, which would
+	// otherwise overwrite duplicates or panic on overlapping patterns
+	eps, conflicts := t.checkConflicts(eps)
+	errs = append(errs, conflicts...)
+	for _, ep := range eps {
+		t.registerEndpoint(ep)
+	}
+	return errs
+}
 
-			// Build full path
-			fullPath := strings.TrimSuffix(baseURL, "/") + apiURL
-			if fullPath == "" {
-				fullPath = "/"
-			}
+// serviceEndpoints : Builds the endpoints of a service from its spec
+func (s *App) serviceEndpoints(spec *ServiceSpec, group *Group) (eps []endpoint, errs RegistrationErrors) {
+	groupPrefix := group.fullPrefix()
+	groupMiddlewares := group.middlewareChain()
 
-			apiVersion := fieldType.Tag.Get("v")
-			if apiVersion == "" {
-				if version != "" {
-					apiVersion = version
-				} else {
-					apiVersion = "1"
-				}
-			}
+	baseURL := spec.Base
+	if baseURL == "" {
+		baseURL = "/"
+	}
 
-			// Get endpoint-level middlewares
-			apiMiddleware := fieldType.Tag.Get("middleware")
-			var endpointMiddlewares []Middleware
-			if len(apiMiddleware) > 0 {
-				names := strings.Split(apiMiddleware, ",")
-				for _, middlewareName := range names {
-					name := strings.Trim(middlewareName, " ")
-					mw, ok := s.middleware[name]
-					if !ok {
-						s.Logger.Error(nil, "Middleware not registered", "name", name)
-						continue
-					}
-					endpointMiddlewares = append(endpointMiddlewares, mw)
-				}
-			}
+	// These middlewares run for specified modules only
+	moduleMiddleware := spec.Middleware
+
+	// Get module-level middlewares
+	moduleMiddlewares, moduleMiddlewareNames, missing := s.resolveMiddlewares(moduleMiddleware)
+	if len(missing) > 0 {
+		// Skip the whole service rather than serve it without e.g. auth
+		errs = append(errs, &RegistrationError{
+			Service: spec.Name,
+			Field:   reflect.TypeOf(Module{}).Name(),
+			Tag:     fmt.Sprintf("middleware:%q", moduleMiddleware),
+			Reason:  "middleware not registered: " + strings.Join(missing, ", "),
+		})
+		return nil, errs
+	}
 
-			handler, ok := checkAPIMethodExists(serviceValue, serviceType, fieldType)
-			if !ok {
-				s.Logger.Error(nil, "Handler not found", "name", fieldType.Name)
-				continue
+	for _, api := range spec.Endpoints {
+		apiURL := api.URL
+		if apiURL == "" {
+			apiURL = "/"
+		}
+
+		// Build full path
+		fullPath := groupPrefix + strings.TrimSuffix(baseURL, "/") + apiURL
+		if fullPath == "" {
+			fullPath = "/"
+		}
+
+		// Strip typed wildcards such as {id:int} down to mux patterns
+		fullPath, constraints, err := s.parsePattern(fullPath)
+		fullPath = exactPattern(fullPath)
+		if err != nil {
+			errs = append(errs, &RegistrationError{
+				Service: spec.Name,
+				Field:   api.Field,
+				Tag:     fmt.Sprintf("url:%q", apiURL),
+				Reason:  err.Error(),
+			})
+			continue
+		}
+
+		hostTag := api.Host
+		if hostTag == "" {
+			hostTag = spec.Host
+		}
+		host, err := parseHost(hostTag)
+		if err != nil {
+			errs = append(errs, &RegistrationError{
+				Service: spec.Name,
+				Field:   api.Field,
+				Tag:     fmt.Sprintf("host:%q", hostTag),
+				Reason:  err.Error(),
+			})
+			continue
+		}
+
+		apiVersion := api.Version
+		if apiVersion == "" {
+			if spec.Version != "" {
+				apiVersion = spec.Version
+			} else {
+				apiVersion = s.DefaultVersion
 			}
+		}
 
-			// Combine all middlewares: global + module + endpoint
-			allMiddlewares := make([]Middleware, 0)
-			allMiddlewares = append(allMiddlewares, s.globalMiddlewares...)
-			allMiddlewares = append(allMiddlewares, moduleMiddlewares...)
-			allMiddlewares = append(allMiddlewares, endpointMiddlewares...)
+		mtls, err := requiresMTLS(spec.MTLS, api.MTLS)
+		if err == nil && mtls && !s.verifiesClientCerts() {
+			// Every request would be refused with 403
+			err = errNoClientCA
+		}
+		if err != nil {
+			errs = append(errs, &RegistrationError{
+				Service: spec.Name,
+				Field:   api.Field,
+				Tag:     fmt.Sprintf("mtls:%q", api.MTLS),
+				Reason:  err.Error(),
+			})
+			continue
+		}
 
-			// Wrap handler with all middlewares
-			wrappedHandler := s.wrapWithMiddlewares(*handler, allMiddlewares)
+		// Get endpoint-level middlewares
+		apiMiddleware := api.Middleware
+		endpointMiddlewares, endpointMiddlewareNames, missing := s.resolveMiddlewares(apiMiddleware)
+		if len(missing) > 0 {
+			errs = append(errs, &RegistrationError{
+				Service: spec.Name,
+				Field:   api.Field,
+				Tag:     fmt.Sprintf("middleware:%q", apiMiddleware),
+				Reason:  "middleware not registered: " + strings.Join(missing, ", "),
+			})
+			continue
+		}
 
-			// Register the route with method-specific handling
-			method := strings.ToUpper(fieldType.Type.String()[5:]) // Remove "neon." prefix
-			s.registerRoute(method, fullPath, wrappedHandler)
+		if api.Handler == nil {
+			reason := api.invalid
+			if reason == "" {
+				reason = "handler method " + api.HandlerName + " not found"
+			}
+			s.Logger.Error(nil, "Invalid handler", "name", api.Field, "reason", reason)
+			errs = append(errs, &RegistrationError{
+				Service: spec.Name,
+				Field:   api.Field,
+				Reason:  reason,
+			})
+			continue
+		}
+
+		// Combine all middlewares: global + group + module + endpoint
+		allMiddlewares := make([]Middleware, 0)
+		allMiddlewares = append(allMiddlewares, s.globalMiddlewares...)
+		allMiddlewares = append(allMiddlewares, groupMiddlewares...)
+		if mtls {
+			// Callers are authenticated before any service middleware runs
+			allMiddlewares = append(allMiddlewares, RequireClientCert)
 		}
+		allMiddlewares = append(allMiddlewares, moduleMiddlewares...)
+		allMiddlewares = append(allMiddlewares, endpointMiddlewares...)
+
+		// Wrap handler with all middlewares
+		wrappedHandler := s.wrapWithMiddlewares(api.Handler, allMiddlewares)
+
+		eps = append(eps, endpoint{
+			method:              api.Method,
+			url:                 fullPath,
+			version:             normalizeVersion(apiVersion),
+			host:                host,
+			constraints:         constraints,
+			handler:             wrappedHandler,
+			service:             spec.Name,
+			field:               api.Field,
+			handlerName:         api.HandlerName,
+			mtls:                mtls,
+			globalMiddlewares:   middlewareNames(s.globalMiddlewares),
+			groupMiddlewares:    middlewareNames(groupMiddlewares),
+			moduleMiddlewares:   moduleMiddlewareNames,
+			endpointMiddlewares: endpointMiddlewareNames,
+		})
 	}
+	return eps, errs
 }
 
 // wrapWithMiddlewares applies middlewares in reverse order (outermost first)
@@ -239,58 +503,313 @@ func (s *App) wrapWithMiddlewares(handler http.HandlerFunc, middlewares []Middle
 	return wrapped.ServeHTTP
 }
 
-// registerRoute registers a route with method checking
-func (s *App) registerRoute(method, path string, handler http.HandlerFunc) {
-	// Initialize path map if it doesn't exist
-	if s.routes[path] == nil {
-		s.routes[path] = make(map[string]http.HandlerFunc)
+// Build compiles all registered services and middlewares into a new route
+// table and swaps it in atomically; requests in flight finish on the table
+// they started with. Services and middlewares added after a previous Build
+// are picked up.
+// Problems are returned as RegistrationErrors; the remaining routes are still
+// installed so callers can decide whether to serve them.
+func (s *App) Build() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, errs := s.build()
+	s.current.Store(t)
+	s.buildErrs = errs
+	return errs.errOrNil()
+}
 
-		// Create a dispatcher handler for this path
-		dispatcher := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if methodHandler, exists := s.routes[path][r.Method]; exists {
-				methodHandler(w, r)
-			} else {
-				http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-			}
-		})
+// build must be called with s.mu held
+func (s *App) build() (*routeTable, RegistrationErrors) {
+	t := newRouteTable(s)
+	errs := s.loadAllServices(t)
+	t.registerSlashAliases()
+	return t, errs
+}
+
+// registrations : The state changed by update, kept to roll a change back
+type registrations struct {
+	services  []serviceEntry
+	providers []*provider
+	mounts    []mountEntry
+	disabled  map[string]bool
+}
+
+// snapshot must be called with s.mu held
+func (s *App) snapshot() registrations {
+	disabled := make(map[string]bool, len(s.disabled))
+	for key := range s.disabled {
+		disabled[key] = true
+	}
+	// Changes append or replace slices, so the headers keep the old contents
+	return registrations{services: s.services, providers: s.providers, mounts: s.mounts, disabled: disabled}
+}
 
-		// Register the dispatcher with the mux
-		s.mux.HandleFunc(path, dispatcher)
+// restore must be called with s.mu held
+func (s *App) restore(r registrations) {
+	s.services, s.providers, s.mounts, s.disabled = r.services, r.providers, r.mounts, r.disabled
+}
+
+// update applies a registration change and, once the app has been built,
+// rebuilds and swaps the route table. Only the errors the change introduced
+// are returned; in Strict mode such a change is rolled back and the previous
+// table is kept.
+func (s *App) update(change func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before := s.snapshot()
+	change()
+	if s.current.Load() == nil {
+		return nil
+	}
+
+	t, errs := s.build()
+	added := errs.without(s.buildErrs)
+	if len(added) > 0 && s.Strict {
+		s.restore(before)
+		return added
+	}
+	s.current.Store(t)
+	s.buildErrs = errs
+	return added.errOrNil()
+}
+
+// table returns the current route table, building it first if needed
+func (s *App) table() *routeTable {
+	if t := s.current.Load(); t != nil {
+		return t
 	}
 
-	// Add the method handler to the path
-	s.routes[path][method] = handler
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.current.Load(); t != nil {
+		return t
+	}
+	t, errs := s.build()
+	if len(errs) > 0 {
+		s.Logger.Error(errs, "Some routes could not be registered")
+	}
+	s.current.Store(t)
+	s.buildErrs = errs
+	return t
+}
 
-	// Count total middlewares for this route
-	totalMiddlewares := len(s.globalMiddlewares)
-	fmt.Printf("%s\t%s\t(%d middlewares)\n", blue(method), yellow(path), totalMiddlewares)
+// Handler builds the app if Build has not been called yet and returns it as an
+// http.Handler. It always serves the latest route table, so it can be mounted
+// on httptest.Server, another mux or any adapter that accepts an http.Handler.
+func (s *App) Handler() http.Handler {
+	s.table()
+	return s
 }
 
+// ServeHTTP implements http.Handler
+func (s *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.table().ServeHTTP(w, withClientIdentity(r))
+}
+
+// Run starts the server and blocks until it stops.
+// SIGINT and SIGTERM trigger a graceful shutdown.
 func (s *App) Run() error {
+	return s.RunContext(context.Background())
+}
+
+// RunContext starts the server and blocks until ctx is cancelled, a SIGINT or
+// SIGTERM is received, or the listener fails. On cancellation it stops
+// accepting connections and waits up to ShutdownTimeout for in-flight
+// requests before running shutdown hooks.
+func (s *App) RunContext(ctx context.Context) error {
+	s.mu.Lock()
+	if s.stopping {
+		s.mu.Unlock()
+		return nil
+	}
+	s.starting = make(chan struct{})
+	done := s.shutdownDoneChan()
+	s.mu.Unlock()
 
 	printLogo()
 	printInfo(s)
-	fmt.Println("Server Starting on Port:", blue(s.Port))
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	bound, err := s.start(ctx)
+	if err != nil {
+		return err
+	}
+
+	go s.watchCertificates(ctx)
+
+	errCh := make(chan error, len(bound))
+	for _, b := range bound {
+		go func(b boundListener) {
+			errCh <- b.serve()
+		}(b)
+	}
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			// Shutdown was called directly; return once it has finished
+			<-done
+			return nil
+		}
+		// One failed listener takes the others down with it
+		log.Printf("Server error: %v", err)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+		defer cancel()
+		return errors.Join(err, s.Shutdown(shutdownCtx))
+	case <-ctx.Done():
+	}
+
+	s.Logger.Info("Shutting down", "timeout", s.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	return s.Shutdown(shutdownCtx)
+}
+
+// start runs the startup steps of RunContext up to publishing the servers of
+// the opened listeners; Shutdown waits for it to return
+func (s *App) start(ctx context.Context) ([]boundListener, error) {
+	defer close(s.starting)
+
+	// Fail before services acquire anything
+	if err := s.checkListeners(); err != nil {
+		return nil, err
+	}
+	if err := s.prepareDevTLS(); err != nil {
+		return nil, err
+	}
+
+	// Services open their resources before routes are built
+	if err := s.InitServices(ctx); err != nil {
+		return nil, s.abortStartup(err)
+	}
 
 	// Add built-in middlewares to global middlewares
+	s.mu.Lock()
 	s.globalMiddlewares = append([]Middleware{requestLogger, panicRecovery}, s.globalMiddlewares...)
+	s.mu.Unlock()
 
 	// Build all Endpoints after middleware registration
 	// This ensures all changes(middlewares) after adding services are also included
-	s.loadAllServices()
-
-	if s.Env == ProdEnv && s.Port == 443 && s.TLSCert != "" && s.TLSKey != "" {
-		err := http.ListenAndServeTLS(fmt.Sprintf(":%d", s.Port), s.TLSCert, s.TLSKey, s.mux)
-		if err != nil {
-			log.Printf("TLS server error: %v", err)
+	if err := s.Build(); err != nil {
+		if s.Strict {
+			return nil, s.abortStartup(err)
 		}
-		return err
+		log.Printf("Starting with registration errors: %v", err)
 	}
-	err := http.ListenAndServe(fmt.Sprintf(":%d", s.Port), s.mux)
+	printRoutes(s)
+
+	if err := s.StartServices(ctx); err != nil {
+		return nil, s.abortStartup(err)
+	}
+
+	bound, err := s.listen()
 	if err != nil {
-		log.Printf("Server error: %v", err)
+		return nil, s.abortStartup(err)
 	}
-	return err
+	servers := make([]*http.Server, 0, len(bound))
+	for _, b := range bound {
+		servers = append(servers, b.srv)
+		fmt.Println("Server Listening on:", blue(b.Listener))
+	}
+	s.mu.Lock()
+	s.servers = servers
+	s.mu.Unlock()
+	return bound, nil
+}
+
+// abortStartup stops the services initialized so far and returns err
+func (s *App) abortStartup(err error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	return errors.Join(err, s.StopServices(ctx))
+}
+
+// OnShutdown registers a hook that runs after the server stops accepting
+// connections. Hooks run in registration order; hooks registered while
+// Shutdown is running may not run. It is safe to call concurrently.
+func (s *App) OnShutdown(fn func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, fn)
+}
+
+// Shutdown gracefully stops the server: it closes listeners, waits for
+// in-flight requests until ctx expires, stops services in reverse registration
+// order and then runs shutdown hooks in order.
+// Called while RunContext is starting, it waits for the listeners to open
+// first; called before RunContext, the server never starts.
+// Only the first call has any effect; later calls wait for it to finish.
+func (s *App) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.stopping = true
+	starting := s.starting
+	done := s.shutdownDoneChan()
+	s.mu.Unlock()
+
+	if starting != nil {
+		select {
+		case <-starting:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	first := false
+	s.shutdownOnce.Do(func() {
+		first = true
+		defer close(done)
+
+		s.mu.RLock()
+		servers := s.servers
+		hooks := append([]func(context.Context) error(nil), s.shutdownHooks...)
+		s.mu.RUnlock()
+
+		// Listeners drain in parallel so one slow listener does not eat the others' time
+		serverErrs := make([]error, len(servers))
+		var wg sync.WaitGroup
+		for i, srv := range servers {
+			wg.Add(1)
+			go func(i int, srv *http.Server) {
+				defer wg.Done()
+				if err := srv.Shutdown(ctx); err != nil {
+					serverErrs[i] = fmt.Errorf("server shutdown: %w", err)
+				}
+			}(i, srv)
+		}
+		wg.Wait()
+		if err := errors.Join(serverErrs...); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := s.StopServices(ctx); err != nil {
+			errs = append(errs, err)
+		}
+
+		for _, hook := range hooks {
+			if err := hook(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	if !first {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shutdownDoneChan must be called with s.mu held
+func (s *App) shutdownDoneChan() chan struct{} {
+	if s.shutdownDone == nil {
+		s.shutdownDone = make(chan struct{})
+	}
+	return s.shutdownDone
 }
 
 // Request logger middleware
@@ -1,9 +1,17 @@
 package neon
 
 import (
+	"context"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 )
@@ -20,8 +28,8 @@ func TestNew(t *testing.T) {
 			t.Errorf("Expected default port 8080, got %d", app.Port)
 		}
 
-		if app.mux == nil {
-			t.Error("Expected mux to be initialized")
+		if app.current.Load() != nil {
+			t.Error("Expected routes not to be built before Build")
 		}
 
 		if app.middleware == nil {
@@ -236,13 +244,14 @@ func TestRegisterRoute(t *testing.T) {
 		w.Write([]byte("test"))
 	})
 
-	app.registerRoute("GET", "/test", handler)
+	table := newRouteTable(app)
+	table.registerRoute("GET", "/test", handler)
 
 	// Test that the route was registered
 	req := httptest.NewRequest("GET", "/test", nil)
 	w := httptest.NewRecorder()
 
-	app.mux.ServeHTTP(w, req)
+	table.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", w.Code)
@@ -256,9 +265,305 @@ func TestRegisterRoute(t *testing.T) {
 	req = httptest.NewRequest("POST", "/test", nil)
 	w = httptest.NewRecorder()
 
-	app.mux.ServeHTTP(w, req)
+	table.ServeHTTP(w, req)
 
 	if w.Code != http.StatusMethodNotAllowed {
 		t.Errorf("Expected status 405, got %d", w.Code)
 	}
 }
+
+func TestBuild(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+
+	if err := app.Build(); err != nil {
+		t.Fatalf("Expected Build to succeed, got %v", err)
+	}
+
+	// Building twice must not panic on duplicate mux patterns
+	if err := app.Build(); err != nil {
+		t.Fatalf("Expected second Build to succeed, got %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/test/endpoint", nil)
+	w := httptest.NewRecorder()
+
+	app.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+
+	if w.Body.String() != "test response" {
+		t.Errorf("Expected 'test response', got '%s'", w.Body.String())
+	}
+}
+
+func TestAppServeHTTP(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+
+	// App builds lazily on first request
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/test/endpoint")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/nonexistent")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+// freePort asks the kernel for an unused TCP port
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// waitForServer polls url until the server accepts connections
+func waitForServer(t *testing.T, url string) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Server at %s did not start", url)
+}
+
+type SlowService struct {
+	Module  `base:"/slow"`
+	getSlow Get `url:"/"`
+}
+
+func (s SlowService) GetSlow(w http.ResponseWriter, r *http.Request) {
+	time.Sleep(200 * time.Millisecond)
+	w.Write([]byte("done"))
+}
+
+func TestRunContextGracefulShutdown(t *testing.T) {
+	app := New(&Config{Port: freePort(t)})
+	app.AddService(&TestService{})
+	app.AddService(&SlowService{})
+
+	var hooks []string
+	app.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "first")
+		return nil
+	})
+	app.OnShutdown(func(ctx context.Context) error {
+		hooks = append(hooks, "second")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.RunContext(ctx) }()
+
+	base := "http://127.0.0.1:" + strconv.Itoa(app.Port)
+	waitForServer(t, base+"/test/endpoint")
+
+	// Start a slow request, then cancel while it is in flight
+	type result struct {
+		body string
+		err  error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(base + "/slow/")
+		if err != nil {
+			inFlight <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		inFlight <- result{string(body), err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	res := <-inFlight
+	if res.err != nil {
+		t.Fatalf("Expected in-flight request to complete, got %v", res.err)
+	}
+	if res.body != "done" {
+		t.Errorf("Expected 'done', got '%s'", res.body)
+	}
+
+	if err := <-runErr; err != nil {
+		t.Errorf("Expected RunContext to return nil, got %v", err)
+	}
+
+	if len(hooks) != 2 || hooks[0] != "first" || hooks[1] != "second" {
+		t.Errorf("Expected hooks to run in order [first second], got %v", hooks)
+	}
+}
+
+func TestShutdownDuringStartup(t *testing.T) {
+	app := New(&Config{Port: freePort(t)})
+	app.AddService(&TestService{})
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.RunContext(context.Background()) }()
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	select {
+	case err := <-runErr:
+		if err != nil {
+			t.Errorf("Expected RunContext to return nil, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected RunContext to stop after Shutdown")
+	}
+	if _, err := http.Get("http://127.0.0.1:" + strconv.Itoa(app.Port) + "/test/endpoint"); err == nil {
+		t.Error("Expected the listener to be closed")
+	}
+}
+
+func TestRunContextWaitsForDirectShutdown(t *testing.T) {
+	app := New(&Config{Port: freePort(t)})
+	app.AddService(&TestService{})
+	var hookDone atomic.Bool
+	app.OnShutdown(func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		hookDone.Store(true)
+		return nil
+	})
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.RunContext(context.Background()) }()
+	waitForServer(t, "http://127.0.0.1:"+strconv.Itoa(app.Port)+"/test/endpoint")
+
+	go app.Shutdown(context.Background())
+	if err := <-runErr; err != nil {
+		t.Errorf("Expected RunContext to return nil, got %v", err)
+	}
+	if !hookDone.Load() {
+		t.Error("Expected RunContext to return after shutdown hooks ran")
+	}
+}
+
+func TestShutdownHookErrors(t *testing.T) {
+	app := New()
+	hookErr := errors.New("close db")
+	app.OnShutdown(func(ctx context.Context) error { return hookErr })
+
+	// Shutdown without a running server still runs hooks
+	err := app.Shutdown(context.Background())
+	if !errors.Is(err, hookErr) {
+		t.Errorf("Expected hook error, got %v", err)
+	}
+
+	// Only the first call has any effect
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected second Shutdown to be a no-op, got %v", err)
+	}
+}
+
+func TestOnShutdownDuringShutdown(t *testing.T) {
+	app := New()
+	var ran atomic.Int32
+	app.OnShutdown(func(ctx context.Context) error { ran.Add(1); return nil })
+
+	// Hooks may be registered from other goroutines while Shutdown runs
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			app.OnShutdown(func(ctx context.Context) error { return nil })
+		}()
+	}
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Errorf("Expected Shutdown to succeed, got %v", err)
+	}
+	wg.Wait()
+	if ran.Load() != 1 {
+		t.Errorf("Expected the hook registered before Shutdown to run once, ran %d times", ran.Load())
+	}
+}
+
+func TestMethodNotAllowedSetsAllow(t *testing.T) {
+	app := New()
+	app.AddService(&IntegrationTestService{})
+	app.RegisterMiddleware("Auth", passThrough)
+	app.RegisterMiddleware("RateLimit", passThrough)
+
+	w := serve(app, httptest.NewRequest("DELETE", "/integration/test", nil))
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, HEAD, OPTIONS" {
+		t.Errorf("Unexpected Allow header %q", allow)
+	}
+}
+
+func TestCustomFallbackHandlers(t *testing.T) {
+	app := New()
+	app.AddService(&TestService{})
+	app.AddMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Global", "yes")
+			next.ServeHTTP(w, r)
+		})
+	})
+	app.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"not found"}`))
+	})
+	app.MethodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error":"use ` + w.Header().Get("Allow") + `"}`))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		w := serve(app, httptest.NewRequest("GET", "/missing", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+		if w.Body.String() != `{"error":"not found"}` {
+			t.Errorf("Expected custom body, got '%s'", w.Body.String())
+		}
+		if w.Header().Get("X-Global") != "yes" {
+			t.Error("Expected global middleware to run for NotFound")
+		}
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		w := serve(app, httptest.NewRequest("POST", "/test/endpoint", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", w.Code)
+		}
+		if w.Body.String() != `{"error":"use GET, HEAD, OPTIONS"}` {
+			t.Errorf("Expected custom body, got '%s'", w.Body.String())
+		}
+		if w.Header().Get("X-Global") != "yes" {
+			t.Error("Expected global middleware to run for MethodNotAllowed")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package neon
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// TrailingSlashPolicy : Selects how a path differing from a route only by a
+// trailing slash is handled
+type TrailingSlashPolicy int
+
+const (
+	// RedirectTrailingSlash answers with a 308 redirect to the path as declared
+	RedirectTrailingSlash TrailingSlashPolicy = iota
+	// StrictTrailingSlash treats /users and /users/ as different paths
+	StrictTrailingSlash
+	// IgnoreTrailingSlash serves both forms from the same route
+	IgnoreTrailingSlash
+)
+
+func (p TrailingSlashPolicy) String() (out string) {
+	switch p {
+	case RedirectTrailingSlash:
+		out = "Redirect"
+	case StrictTrailingSlash:
+		out = "Strict"
+	case IgnoreTrailingSlash:
+		out = "Ignore"
+	default:
+		out = "Not Defined"
+	}
+	return
+}
+
+// exactPattern anchors a pattern ending in "/" with {$}, so url:"/" matches
+// only the path itself instead of the whole subtree; {name...} is the way
+// to ask for a subtree
+func exactPattern(pattern string) string {
+	if strings.HasSuffix(pattern, "/") {
+		return pattern + "{$}"
+	}
+	return pattern
+}
+
+// declaredPath reverses exactPattern, giving back the path as it was declared
+func declaredPath(pattern string) string {
+	if strings.HasSuffix(pattern, "/{$}") {
+		return strings.TrimSuffix(pattern, "{$}")
+	}
+	return pattern
+}
+
+// slashAlias returns the pattern matching pattern's path with the trailing
+// slash toggled, or "" when there is none (root, subtree and catch-all patterns)
+func slashAlias(pattern string) string {
+	switch {
+	case pattern == "/{$}", strings.HasSuffix(pattern, "/"), strings.HasSuffix(pattern, "...}"):
+		return ""
+	case strings.HasSuffix(pattern, "/{$}"):
+		return strings.TrimSuffix(pattern, "/{$}")
+	default:
+		return pattern + "/{$}"
+	}
+}
+
+// registerSlashAliases registers the trailing slash counterpart of every
+// route according to TrailingSlash. Routes declared explicitly and patterns
+// the mux would reject take precedence over aliases.
+func (t *routeTable) registerSlashAliases() {
+	if t.config.trailingSlash == StrictTrailingSlash {
+		return
+	}
+	for path, dispatcher := range t.dispatchers {
+		alias := slashAlias(path)
+		if alias == "" {
+			continue
+		}
+		if _, declared := t.dispatchers[alias]; declared {
+			continue
+		}
+
+		handler := dispatcher
+		if t.config.trailingSlash == RedirectTrailingSlash {
+			handler = slashRedirect(strings.HasSuffix(path, "/{$}"))
+		}
+		if err := tryHandle(t.mux, alias, handler); err != nil {
+			t.app.Logger.V(1).Info("Skipping trailing slash alias", "pattern", alias, "reason", err.Error())
+		}
+	}
+}
+
+// slashRedirect redirects to the request path with a trailing slash added or removed
+// The path is the one the client sent, before a Mount stripped its prefix
+func slashRedirect(addSlash bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.EscapedPath()
+		if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+			target = u.EscapedPath()
+		}
+		if addSlash {
+			target += "/"
+		} else {
+			target = strings.TrimSuffix(target, "/")
+		}
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	}
+}
+
+// isSlashRedirect reports whether the mux is about to redirect to add a trailing
+// slash that StrictTrailingSlash forbids. Depending on the Go version ServeMux
+// reports either the redirect target path or the matched pattern; subtree
+// patterns of mounts keep their redirect.
+func (t *routeTable) isSlashRedirect(r *http.Request, pattern string) bool {
+	if t.config.trailingSlash != StrictTrailingSlash || strings.TrimSuffix(pattern, "{$}") != r.URL.Path+"/" {
+		return false
+	}
+	_, subtree := t.dispatchers[pattern]
+	return !subtree || !strings.HasSuffix(pattern, "/")
+}
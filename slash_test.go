@@ -0,0 +1,148 @@
+package neon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type SlashService struct {
+	Module    `base:"/users"`
+	listUsers Get  `url:"/"`
+	getUser   Get  `url:"/{id}"`
+	getFiles  Get  `url:"/{id}/files/{path...}"`
+	addUser   Post `url:"/"`
+}
+
+func (s SlashService) ListUsers(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("list"))
+}
+
+func (s SlashService) GetUser(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("user " + r.PathValue("id")))
+}
+
+func (s SlashService) GetFiles(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("files " + r.PathValue("path")))
+}
+
+func (s SlashService) AddUser(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("added"))
+}
+
+func newSlashApp(policy TrailingSlashPolicy) *App {
+	app := New()
+	app.TrailingSlash = policy
+	app.AddService(&SlashService{})
+	return app
+}
+
+func TestExactMatchForSlashURL(t *testing.T) {
+	app := newSlashApp(StrictTrailingSlash)
+
+	if w := serve(app, httptest.NewRequest("GET", "/users/", nil)); w.Body.String() != "list" {
+		t.Errorf("Expected 'list', got '%s'", w.Body.String())
+	}
+	// url:"/" no longer matches the whole subtree
+	if w := serve(app, httptest.NewRequest("GET", "/users/1/extra", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+	// Explicit catch-all still does
+	if w := serve(app, httptest.NewRequest("GET", "/users/1/files/a/b", nil)); w.Body.String() != "files a/b" {
+		t.Errorf("Expected 'files a/b', got '%s'", w.Body.String())
+	}
+}
+
+func TestStrictTrailingSlash(t *testing.T) {
+	app := newSlashApp(StrictTrailingSlash)
+
+	for _, path := range []string{"/users", "/users/1/"} {
+		if w := serve(app, httptest.NewRequest("GET", path, nil)); w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected status 404, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	app := newSlashApp(RedirectTrailingSlash)
+
+	tests := []struct {
+		method   string
+		path     string
+		location string
+	}{
+		{"GET", "/users", "/users/"},
+		{"POST", "/users?notify=1", "/users/?notify=1"},
+		{"GET", "/users/1/", "/users/1"},
+	}
+
+	for _, test := range tests {
+		w := serve(app, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s %s: expected status 308, got %d", test.method, test.path, w.Code)
+		}
+		if loc := w.Header().Get("Location"); loc != test.location {
+			t.Errorf("%s %s: expected Location %s, got %s", test.method, test.path, test.location, loc)
+		}
+	}
+}
+
+func TestTrailingSlashAppliesOnBuild(t *testing.T) {
+	app := newSlashApp(StrictTrailingSlash)
+	app.Build()
+
+	app.TrailingSlash = IgnoreTrailingSlash
+	if w := serve(app, httptest.NewRequest("GET", "/users", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 before rebuilding, got %d", w.Code)
+	}
+	app.Build()
+	if w := serve(app, httptest.NewRequest("GET", "/users", nil)); w.Body.String() != "list" {
+		t.Errorf("Expected 'list' after rebuilding, got '%s'", w.Body.String())
+	}
+}
+
+func TestIgnoreTrailingSlash(t *testing.T) {
+	app := newSlashApp(IgnoreTrailingSlash)
+
+	tests := map[string]string{
+		"/users":    "list",
+		"/users/":   "list",
+		"/users/1":  "user 1",
+		"/users/1/": "user 1",
+	}
+
+	for path, expected := range tests {
+		if w := serve(app, httptest.NewRequest("GET", path, nil)); w.Body.String() != expected {
+			t.Errorf("GET %s: expected '%s', got '%s'", path, expected, w.Body.String())
+		}
+	}
+}
+
+func TestSlashAlias(t *testing.T) {
+	tests := map[string]string{
+		"/{$}":             "",
+		"/users/{$}":       "/users",
+		"/users/{id}":      "/users/{id}/{$}",
+		"/files/{path...}": "",
+		"/legacy/":         "",
+	}
+	for in, expected := range tests {
+		if got := slashAlias(in); got != expected {
+			t.Errorf("slashAlias(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestRedirectTrailingSlashInMountedApp(t *testing.T) {
+	app := New()
+	app.Mount("/admin", newSlashApp(RedirectTrailingSlash))
+
+	w := serve(app, httptest.NewRequest("GET", "/admin/users?page=2", nil))
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/admin/users/?page=2" {
+		t.Errorf("Expected redirect to /admin/users/?page=2, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+	w = serve(app, httptest.NewRequest("GET", "/admin/users/1/", nil))
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "/admin/users/1" {
+		t.Errorf("Expected redirect to /admin/users/1, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
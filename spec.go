@@ -0,0 +1,113 @@
+package neon
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// ServiceSpec : Static description of a service, mirroring its struct tags
+// `neon gen` emits one per service so it can be registered without reflection;
+// AddService derives the same description from the struct at build time
+type ServiceSpec struct {
+	// Service identifies the registration for RemoveService and receives
+	// lifecycle calls and injected dependencies; it must be a pointer, or nil
+	Service Moduler
+	// Name is reported in errors and Routes, e.g. "main.UserService"
+	Name string
+
+	// Module tags
+	Base       string
+	Version    string
+	Host       string
+	Middleware string
+	MTLS       string
+
+	Endpoints []EndpointSpec
+}
+
+// EndpointSpec : Static description of an endpoint field
+type EndpointSpec struct {
+	// Method is the HTTP method served, or MethodAny
+	Method string
+	// Field and HandlerName are reported in errors and Routes
+	Field       string
+	HandlerName string
+	Handler     http.HandlerFunc
+
+	// Endpoint tags
+	URL        string
+	Version    string
+	Host       string
+	Middleware string
+	MTLS       string
+
+	// invalid explains why the reflected handler could not be bound
+	invalid string
+}
+
+// Registrar : Accepts generated service registrations; implemented by App and Group
+type Registrar interface {
+	AddServiceSpec(spec ServiceSpec) error
+}
+
+// AddServiceSpec : Add a statically described service to app, see ServiceSpec
+// Registration then follows the same rules as AddService
+func (s *App) AddServiceSpec(spec ServiceSpec) error {
+	return s.addServiceSpec(spec, nil)
+}
+
+// AddServiceSpec : Add a statically described service to the group, see ServiceSpec
+func (g *Group) AddServiceSpec(spec ServiceSpec) error {
+	return g.app.addServiceSpec(spec, g)
+}
+
+func (s *App) addServiceSpec(spec ServiceSpec, group *Group) error {
+	if spec.Service != nil && reflect.TypeOf(spec.Service).Kind() != reflect.Ptr {
+		return s.reject(&RegistrationError{Service: spec.Name, Reason: "ServiceSpec.Service should be a pointer"})
+	}
+	return s.addEntry(serviceEntry{service: spec.Service, spec: &spec, group: group})
+}
+
+// reflectServiceSpec describes a service validated by validateService from its struct tags
+func reflectServiceSpec(service Moduler) *ServiceSpec {
+	serviceType := reflect.TypeOf(service).Elem()
+	serviceValue := reflect.ValueOf(service)
+
+	// Get Module level Configurations
+	field, _ := serviceType.FieldByName(reflect.TypeOf(Module{}).Name())
+	spec := &ServiceSpec{
+		Service:    service,
+		Name:       serviceType.String(),
+		Base:       field.Tag.Get("base"),
+		Version:    field.Tag.Get("v"),
+		Host:       field.Tag.Get("host"),
+		Middleware: field.Tag.Get("middleware"),
+		MTLS:       field.Tag.Get("mtls"),
+	}
+
+	for i := 0; i < serviceType.NumField(); i++ {
+		fieldType := serviceType.Field(i)
+		method, ok := endpointMethod(fieldType.Type)
+		if !ok {
+			continue
+		}
+
+		ep := EndpointSpec{
+			Method:      method,
+			Field:       fieldType.Name,
+			HandlerName: handlerMethodName(fieldType),
+			URL:         fieldType.Tag.Get("url"),
+			Version:     fieldType.Tag.Get("v"),
+			Host:        fieldType.Tag.Get("host"),
+			Middleware:  fieldType.Tag.Get("middleware"),
+			MTLS:        fieldType.Tag.Get("mtls"),
+		}
+		if handler, err := checkAPIMethodExists(serviceValue, fieldType); err == nil {
+			ep.Handler = *handler
+		} else {
+			ep.invalid = err.Error()
+		}
+		spec.Endpoints = append(spec.Endpoints, ep)
+	}
+	return spec
+}
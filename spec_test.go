@@ -0,0 +1,146 @@
+package neon
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// integrationSpec describes IntegrationTestService the way `neon gen` would
+func integrationSpec(s *IntegrationTestService) ServiceSpec {
+	return ServiceSpec{
+		Service:    s,
+		Name:       "neon.IntegrationTestService",
+		Base:       "/integration",
+		Version:    "1",
+		Middleware: "Auth",
+		Endpoints: []EndpointSpec{
+			{Method: http.MethodGet, Field: "getTest", HandlerName: "GetTest", Handler: func(w http.ResponseWriter, r *http.Request) { s.GetTest(w, r) }, URL: "/test"},
+			{Method: http.MethodPost, Field: "createTest", HandlerName: "CreateTest", Handler: func(w http.ResponseWriter, r *http.Request) { s.CreateTest(w, r) }, URL: "/create", Middleware: "RateLimit"},
+		},
+	}
+}
+
+func TestAddServiceSpecMatchesAddService(t *testing.T) {
+	reflected := New()
+	reflected.RegisterMiddleware("Auth", passThrough)
+	reflected.RegisterMiddleware("RateLimit", passThrough)
+	reflected.AddService(&IntegrationTestService{})
+	if err := reflected.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	static := New()
+	static.RegisterMiddleware("Auth", passThrough)
+	static.RegisterMiddleware("RateLimit", passThrough)
+	if err := static.AddServiceSpec(integrationSpec(&IntegrationTestService{})); err != nil {
+		t.Fatalf("AddServiceSpec failed: %v", err)
+	}
+	if err := static.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(static.Routes(), reflected.Routes()) {
+		t.Errorf("Expected the same routes as AddService\ngot:  %+v\nwant: %+v", static.Routes(), reflected.Routes())
+	}
+
+	w := serve(static, httptest.NewRequest("GET", "/integration/test", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "integration test response" {
+		t.Errorf("Expected 200 'integration test response', got %d '%s'", w.Code, w.Body.String())
+	}
+}
+
+func TestAddServiceSpecErrors(t *testing.T) {
+	app := New()
+	spec := integrationSpec(&IntegrationTestService{})
+	app.AddServiceSpec(spec)
+
+	var errs RegistrationErrors
+	if !errors.As(app.Build(), &errs) || len(errs) != 1 {
+		t.Fatalf("Expected one registration error, got %v", errs)
+	}
+	if errs[0].Service != "neon.IntegrationTestService" || errs[0].Field != "Module" {
+		t.Errorf("Expected module middleware error, got %v", errs[0])
+	}
+
+	// An endpoint without a handler is reported instead of served
+	app = New()
+	spec.Middleware = ""
+	spec.Endpoints = []EndpointSpec{{Method: http.MethodGet, Field: "getTest", HandlerName: "GetTest", URL: "/test"}}
+	app.AddServiceSpec(spec)
+	if !errors.As(app.Build(), &errs) || errs[0].Reason != "handler method GetTest not found" {
+		t.Errorf("Expected missing handler error, got %v", errs)
+	}
+}
+
+func TestAddServiceSpecNonPointerService(t *testing.T) {
+	app := New()
+	spec := ServiceSpec{
+		Service:   TestService{},
+		Name:      "neon.TestService",
+		Endpoints: []EndpointSpec{{Method: http.MethodGet, Field: "getTest", HandlerName: "GetTest", Handler: TestService{}.GetTest, URL: "/test"}},
+	}
+	if err := app.AddServiceSpec(spec); err == nil {
+		t.Fatal("Expected AddServiceSpec to reject a non-pointer service")
+	}
+
+	var errs RegistrationErrors
+	if !errors.As(app.Build(), &errs) || len(errs) != 1 || errs[0].Service != "neon.TestService" {
+		t.Errorf("Expected Build to report the rejected service, got %v", errs)
+	}
+}
+
+func TestGroupAddServiceSpec(t *testing.T) {
+	app := New()
+	app.RegisterMiddleware("Auth", passThrough)
+	app.RegisterMiddleware("RateLimit", passThrough)
+	service := &IntegrationTestService{}
+	app.Group("/api").AddServiceSpec(integrationSpec(service))
+	app.Build()
+
+	if w := serve(app, httptest.NewRequest("GET", "/api/integration/test", nil)); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 under group prefix, got %d", w.Code)
+	}
+
+	if err := app.RemoveService(service); err != nil {
+		t.Fatalf("RemoveService failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/api/integration/test", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after RemoveService, got %d", w.Code)
+	}
+}
+
+type ValueReceiverService struct {
+	Module `base:"/value"`
+	get    Get     `url:"/"`
+	DB     *testDB `inject:""`
+}
+
+func (s ValueReceiverService) Get(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte(s.DB.dsn))
+}
+
+// valueReceiverSpec describes ValueReceiverService the way `neon gen` would
+func valueReceiverSpec(s *ValueReceiverService) ServiceSpec {
+	return ServiceSpec{
+		Service: s,
+		Name:    "neon.ValueReceiverService",
+		Base:    "/value",
+		Endpoints: []EndpointSpec{
+			{Method: http.MethodGet, Field: "get", HandlerName: "Get", Handler: func(w http.ResponseWriter, r *http.Request) { s.Get(w, r) }, URL: "/"},
+		},
+	}
+}
+
+func TestSpecValueReceiverSeesInjectedFields(t *testing.T) {
+	app := New()
+	app.Provide(&testDB{dsn: "primary"})
+	if err := app.AddServiceSpec(valueReceiverSpec(&ValueReceiverService{})); err != nil {
+		t.Fatalf("AddServiceSpec failed: %v", err)
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/value/", nil)); w.Body.String() != "primary" {
+		t.Errorf("Expected injected 'primary', got '%s'", w.Body.String())
+	}
+}
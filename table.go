@@ -0,0 +1,132 @@
+package neon
+
+import "net/http"
+
+// routeTable : A compiled route tree
+// Build creates a new table for every change and swaps it in atomically; a
+// table is never modified once published, so the dispatchers reading it need
+// no locking and requests in flight see a consistent snapshot.
+type routeTable struct {
+	app    *App
+	config routeConfig
+
+	mux         *http.ServeMux
+	routes      map[string]map[string]http.HandlerFunc            // path -> method -> handler
+	dispatchers map[string]http.HandlerFunc                       // path -> method dispatcher
+	versions    map[string]map[string]map[string]http.HandlerFunc // path -> method -> version -> handler
+	endpoints   []endpoint                                        // listing for Routes
+
+	globalMiddlewares []Middleware
+
+	// NotFound and MethodNotAllowed wrapped with global middlewares
+	notFound         http.HandlerFunc
+	methodNotAllowed http.HandlerFunc
+}
+
+// routeConfig : The App settings a table routes by, copied when the table is
+// created so changing them takes effect with the next Build
+type routeConfig struct {
+	versioning     VersionStrategy
+	defaultVersion string // normalized
+	versionHeader  string
+	trailingSlash  TrailingSlashPolicy
+}
+
+// newRouteTable creates an empty table using the app's current routing settings,
+// global middlewares and fallback handlers; must be called with app.mu held
+func newRouteTable(app *App) *routeTable {
+	t := &routeTable{
+		app: app,
+		config: routeConfig{
+			versioning:     app.Versioning,
+			defaultVersion: normalizeVersion(app.DefaultVersion),
+			versionHeader:  app.VersionHeader,
+			trailingSlash:  app.TrailingSlash,
+		},
+		mux:               http.NewServeMux(),
+		routes:            make(map[string]map[string]http.HandlerFunc),
+		dispatchers:       make(map[string]http.HandlerFunc),
+		versions:          make(map[string]map[string]map[string]http.HandlerFunc),
+		globalMiddlewares: append([]Middleware(nil), app.globalMiddlewares...),
+	}
+
+	notFound := app.NotFound
+	if notFound == nil {
+		notFound = http.NotFoundHandler()
+	}
+	t.notFound = app.wrapWithMiddlewares(notFound.ServeHTTP, t.globalMiddlewares)
+
+	methodNotAllowed := app.MethodNotAllowed
+	if methodNotAllowed == nil {
+		methodNotAllowed = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		})
+	}
+	t.methodNotAllowed = app.wrapWithMiddlewares(methodNotAllowed.ServeHTTP, t.globalMiddlewares)
+	return t
+}
+
+// ServeHTTP routes a request through the table's mux, answering unmatched
+// requests with the NotFound handler
+func (t *routeTable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := t.mux.Handler(r); pattern == "" || t.isSlashRedirect(r, pattern) {
+		t.notFound(w, r)
+		return
+	}
+	t.mux.ServeHTTP(w, r)
+}
+
+// registerRoute registers a route with method checking
+func (t *routeTable) registerRoute(method, path string, handler http.HandlerFunc) {
+	// Initialize path map if it doesn't exist
+	if t.routes[path] == nil {
+		t.routes[path] = make(map[string]http.HandlerFunc)
+
+		// Automatic OPTIONS runs through global middlewares, wrapped once per path
+		options := t.app.wrapWithMiddlewares(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allowedMethods(t.routes[path]))
+			w.WriteHeader(http.StatusNoContent)
+		}, t.globalMiddlewares)
+
+		// Create a dispatcher handler for this path
+		dispatcher := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlers := t.routes[path]
+			if methodHandler, exists := handlers[r.Method]; exists {
+				methodHandler(w, r)
+				return
+			}
+			switch r.Method {
+			case http.MethodHead:
+				// Serve HEAD from GET; net/http discards the body and keeps Content-Length
+				if getHandler, exists := handlers[http.MethodGet]; exists {
+					getHandler(w, r)
+					return
+				}
+			case http.MethodOptions:
+				if _, exists := handlers[MethodAny]; !exists {
+					options(w, r)
+					return
+				}
+			}
+			if anyHandler, exists := handlers[MethodAny]; exists {
+				anyHandler(w, r)
+				return
+			}
+			w.Header().Set("Allow", allowedMethods(handlers))
+			t.methodNotAllowed(w, r)
+		})
+
+		// Register the dispatcher with the mux
+		t.mux.HandleFunc(path, dispatcher)
+		t.dispatchers[path] = dispatcher
+	}
+
+	// Add the method handler to the path
+	t.routes[path][method] = handler
+}
+
+// recordRoute adds a registered endpoint to the table's listing under path
+func (t *routeTable) recordRoute(ep endpoint, path string) {
+	ep.url = path
+	t.endpoints = append(t.endpoints, ep)
+}
@@ -0,0 +1,190 @@
+package neon
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const defaultCertReloadInterval = 10 * time.Second
+
+var errHalfTLS = errors.New("TLSCert and TLSKey must be set together")
+
+// certReloader : Serves a certificate pair from disk, replaced when the files change
+type certReloader struct {
+	certFile, keyFile string
+
+	cert    atomic.Pointer[tls.Certificate]
+	mu      sync.Mutex // serializes reloads
+	modTime time.Time  // newest modification time of the loaded files
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the pair from disk; the previous certificate is kept on failure
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("neon: load certificate %s: %w", r.certFile, err)
+	}
+	r.cert.Store(&cert)
+	r.modTime = modTime
+	return nil
+}
+
+// changed reports whether either file was modified since the last reload
+func (r *certReloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	modTime, err := r.filesModTime()
+	return err == nil && modTime.After(r.modTime)
+}
+
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var newest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("neon: load certificate: %w", err)
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	return newest, nil
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// hasCertificates reports whether cfg can serve TLS without certificate files
+func hasCertificates(cfg *tls.Config) bool {
+	return cfg != nil && (len(cfg.Certificates) > 0 || cfg.GetCertificate != nil || cfg.GetConfigForClient != nil)
+}
+
+// serverTLSConfig builds the TLS configuration of a TLS listener; certificate
+// files are loaded through a reloader registered with the app
+// Must be called with s.mu held
+func (s *App) serverTLSConfig(l Listener) (*tls.Config, error) {
+	base := l.TLSConfig
+	if base == nil {
+		base = s.TLSConfig
+	}
+	cfg := &tls.Config{}
+	if base != nil {
+		cfg = base.Clone()
+	}
+	if err := s.applyClientCA(cfg); err != nil {
+		return nil, err
+	}
+	if l.TLSCert == "" {
+		return cfg, nil
+	}
+
+	reloader, err := s.certReloader(l.TLSCert, l.TLSKey)
+	if err != nil {
+		return nil, err
+	}
+	// GetCertificate is only consulted without static Certificates
+	cfg.Certificates = nil
+	cfg.GetCertificate = reloader.GetCertificate
+	return cfg, nil
+}
+
+// certReloader returns the reloader for a certificate pair, shared between listeners
+// Must be called with s.mu held
+func (s *App) certReloader(certFile, keyFile string) (*certReloader, error) {
+	for _, r := range s.certs {
+		if r.certFile == certFile && r.keyFile == keyFile {
+			return r, nil
+		}
+	}
+	r, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	s.certs = append(s.certs, r)
+	return r, nil
+}
+
+// ReloadCertificates reloads every certificate file pair served by the app.
+// A pair that fails to load keeps serving the previous certificate.
+// RunContext also reloads on SIGHUP and when the files change on disk.
+func (s *App) ReloadCertificates() error {
+	s.mu.RLock()
+	certs := append([]*certReloader(nil), s.certs...)
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, r := range certs {
+		if err := r.reload(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s.Logger.Info("TLS certificate reloaded", "cert", r.certFile)
+	}
+	return errors.Join(errs...)
+}
+
+// watchCertificates reloads certificates on SIGHUP and, every
+// CertReloadInterval, when their files changed, until ctx is done
+func (s *App) watchCertificates(ctx context.Context) {
+	s.mu.RLock()
+	certs := append([]*certReloader(nil), s.certs...)
+	interval := s.CertReloadInterval
+	s.mu.RUnlock()
+	if len(certs) == 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultCertReloadInterval
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := s.ReloadCertificates(); err != nil {
+				s.Logger.Error(err, "TLS certificate reload failed")
+			}
+		case <-ticker.C:
+			for _, r := range certs {
+				if !r.changed() {
+					continue
+				}
+				if err := r.reload(); err != nil {
+					s.Logger.Error(err, "TLS certificate reload failed", "cert", r.certFile)
+					continue
+				}
+				s.Logger.Info("TLS certificate reloaded", "cert", r.certFile)
+			}
+		}
+	}
+}
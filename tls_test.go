@@ -0,0 +1,135 @@
+package neon
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// freshTLSClient opens a new connection per request so every request sees the current certificate
+func freshTLSClient(cfg *tls.Config) *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: cfg, DisableKeepAlives: true}}
+}
+
+// servedSerial returns the serial number of the certificate served at url
+func servedSerial(t *testing.T, url string) *big.Int {
+	t.Helper()
+	resp, _ := getBody(t, freshTLSClient(&tls.Config{InsecureSkipVerify: true}), url)
+	if resp.TLS == nil {
+		t.Fatalf("Expected TLS response from %s", url)
+	}
+	return resp.TLS.PeerCertificates[0].SerialNumber
+}
+
+// runApp runs app until the test ends
+func runApp(t *testing.T, app *App) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- app.RunContext(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-runErr; err != nil {
+			t.Errorf("RunContext failed: %v", err)
+		}
+	})
+}
+
+func TestTLSOutsideProdEnv(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir())
+	app := New(&Config{Port: freePort(t), TLSCert: certFile, TLSKey: keyFile})
+	app.SetEnv(DevEnv)
+	app.AddService(&TestService{})
+	runApp(t, app)
+
+	resp, body := getBody(t, insecureClient, "https://127.0.0.1:"+strconv.Itoa(app.Port)+"/test/endpoint")
+	if resp.TLS == nil || body != "test response" {
+		t.Errorf("Expected 'test response' over TLS, got '%s'", body)
+	}
+}
+
+func TestHalfTLSConfigFailsStartup(t *testing.T) {
+	certFile, _ := writeTestCert(t, t.TempDir())
+	var log []string
+	app := New(&Config{Port: freePort(t), TLSCert: certFile})
+	app.AddService(&LifecycleService{name: "a", log: &log})
+
+	err := app.RunContext(context.Background())
+	if !errors.Is(err, errHalfTLS) {
+		t.Fatalf("Expected error for a certificate without key, got %v", err)
+	}
+	if len(log) != 0 {
+		t.Errorf("Expected no service to be initialized, got %v", log)
+	}
+
+	app = New(&Config{Listeners: []Listener{{Addr: ":0", TLSKey: "key.pem"}}})
+	if err := app.RunContext(context.Background()); !errors.Is(err, errHalfTLS) {
+		t.Errorf("Expected error for a listener key without certificate, got %v", err)
+	}
+}
+
+func TestTLSConfigOverride(t *testing.T) {
+	certFile, keyFile := writeTestCert(t, t.TempDir())
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Certificates in TLSConfig serve HTTPS without files
+	app := New(&Config{Port: freePort(t), TLSConfig: &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+	}})
+	app.AddService(&TestService{})
+	runApp(t, app)
+
+	url := "https://127.0.0.1:" + strconv.Itoa(app.Port) + "/test/endpoint"
+	if resp, _ := getBody(t, insecureClient, url); resp.TLS.Version != tls.VersionTLS13 {
+		t.Errorf("Expected TLS 1.3, got %x", resp.TLS.Version)
+	}
+	old := freshTLSClient(&tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12})
+	if _, err := old.Get(url); err == nil {
+		t.Error("Expected TLS 1.2 client to be rejected by MinVersion")
+	}
+}
+
+func TestCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+	app := New(&Config{Port: freePort(t), TLSCert: certFile, TLSKey: keyFile})
+	app.CertReloadInterval = 20 * time.Millisecond
+	app.AddService(&TestService{})
+	runApp(t, app)
+
+	url := "https://127.0.0.1:" + strconv.Itoa(app.Port) + "/test/endpoint"
+	first := servedSerial(t, url)
+
+	// Rewriting the files is picked up without a restart
+	writeTestCert(t, dir)
+	future := time.Now().Add(time.Second)
+	os.Chtimes(certFile, future, future)
+	var second *big.Int
+	for i := 0; i < 100; i++ {
+		if second = servedSerial(t, url); second.Cmp(first) != 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if second.Cmp(first) == 0 {
+		t.Fatal("Expected the rewritten certificate to be served")
+	}
+
+	// A broken pair keeps the current certificate
+	os.WriteFile(certFile, []byte("not a certificate"), 0o600)
+	if err := app.ReloadCertificates(); err == nil {
+		t.Error("Expected reload of a broken certificate to fail")
+	}
+	if got := servedSerial(t, url); got.Cmp(second) != 0 {
+		t.Errorf("Expected certificate %v to keep being served, got %v", second, got)
+	}
+}
@@ -0,0 +1,39 @@
+//go:build unix
+
+package neon
+
+import (
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestCertificateReloadOnSIGHUP(t *testing.T) {
+	// Keep SIGHUP from terminating the test binary before the app listens for it
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGHUP)
+	defer signal.Stop(guard)
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+	app := New(&Config{Port: freePort(t), TLSCert: certFile, TLSKey: keyFile})
+	app.CertReloadInterval = time.Hour
+	app.AddService(&TestService{})
+	runApp(t, app)
+
+	url := "https://127.0.0.1:" + strconv.Itoa(app.Port) + "/test/endpoint"
+	first := servedSerial(t, url)
+	writeTestCert(t, dir)
+
+	for i := 0; i < 100; i++ {
+		syscall.Kill(os.Getpid(), syscall.SIGHUP)
+		time.Sleep(10 * time.Millisecond)
+		if servedSerial(t, url).Cmp(first) != 0 {
+			return
+		}
+	}
+	t.Fatal("Expected SIGHUP to reload the certificate")
+}
@@ -0,0 +1,151 @@
+package neon
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// VersionStrategy : Selects how the API version of a request is determined
+type VersionStrategy int
+
+const (
+	// NoVersioning ignores `v` tags; every version shares the same path
+	NoVersioning VersionStrategy = iota
+	// PathVersioning prefixes routes with the version, e.g. /v1/users
+	PathVersioning
+	// AcceptVersioning reads the version parameter of the Accept media type,
+	// e.g. Accept: application/json; version=2
+	AcceptVersioning
+	// HeaderVersioning reads the version from App.VersionHeader
+	HeaderVersioning
+)
+
+func (v VersionStrategy) String() (out string) {
+	switch v {
+	case NoVersioning:
+		out = "None"
+	case PathVersioning:
+		out = "Path"
+	case AcceptVersioning:
+		out = "Accept"
+	case HeaderVersioning:
+		out = "Header"
+	default:
+		out = "Not Defined"
+	}
+	return
+}
+
+const (
+	defaultVersion       = "1"
+	defaultVersionHeader = "X-API-Version"
+	acceptVersionParam   = "version"
+)
+
+// normalizeVersion strips an optional "v" prefix so "v2", "V2" and "2" match
+func normalizeVersion(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) > 1 && (v[0] == 'v' || v[0] == 'V') {
+		v = v[1:]
+	}
+	return v
+}
+
+// versionedPaths returns the paths an endpoint is served on under the versioning strategy
+func (c routeConfig) versionedPaths(ep endpoint) []string {
+	if c.versioning != PathVersioning || ep.mounted != nil {
+		return []string{ep.url}
+	}
+	paths := []string{"/v" + ep.version + ep.url}
+	// Requests without a version prefix are served by the default version
+	if ep.version == c.defaultVersion {
+		paths = append(paths, ep.url)
+	}
+	return paths
+}
+
+// negotiatesVersion reports whether versions share paths and are picked per request
+func (c routeConfig) negotiatesVersion() bool {
+	return c.versioning == AcceptVersioning || c.versioning == HeaderVersioning
+}
+
+// registerEndpoint registers an endpoint according to the table's versioning strategy
+func (t *routeTable) registerEndpoint(ep endpoint) {
+	ep.version = normalizeVersion(ep.version)
+	method, version, handler := ep.method, ep.version, http.HandlerFunc(ep.handler)
+	if ep.conditional() || len(ep.chain) > 0 {
+		handler = t.chainHandler(append([]endpoint{ep}, ep.chain...))
+	}
+	for _, path := range t.config.versionedPaths(ep) {
+		if t.config.negotiatesVersion() && ep.mounted == nil {
+			if t.versions[path] == nil {
+				t.versions[path] = make(map[string]map[string]http.HandlerFunc)
+			}
+			if t.versions[path][method] == nil {
+				t.versions[path][method] = make(map[string]http.HandlerFunc)
+				t.registerRoute(method, path, t.versionDispatcher(path, method))
+			}
+			t.versions[path][method][version] = handler
+		} else {
+			t.registerRoute(method, path, handler)
+		}
+		t.recordRoute(ep, path)
+	}
+	for _, next := range ep.chain {
+		for _, path := range t.config.versionedPaths(next) {
+			t.recordRoute(next, path)
+		}
+	}
+}
+
+// versionDispatcher picks the handler matching the requested version,
+// falling back to the default version when the request names none
+func (t *routeTable) versionDispatcher(path, method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version := t.config.requestVersion(r)
+		if version == "" {
+			version = t.config.defaultVersion
+		}
+		if handler, exists := t.versions[path][method][version]; exists {
+			handler(w, r)
+			return
+		}
+		t.notFound(w, r)
+	}
+}
+
+// requestVersion extracts the version from the request, empty if none was given
+func (c routeConfig) requestVersion(r *http.Request) string {
+	switch c.versioning {
+	case HeaderVersioning:
+		return normalizeVersion(r.Header.Get(c.versionHeader))
+	case AcceptVersioning:
+		for _, accept := range r.Header.Values("Accept") {
+			for _, mediaRange := range strings.Split(accept, ",") {
+				_, params, err := mime.ParseMediaType(mediaRange)
+				if err != nil {
+					continue
+				}
+				if v, ok := params[acceptVersionParam]; ok {
+					return normalizeVersion(v)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// VersionedRoutes returns registered routes grouped by API version,
+// each formatted as "METHOD path" and sorted
+func (s *App) VersionedRoutes() map[string][]string {
+	out := make(map[string][]string)
+	for _, rt := range s.Routes() {
+		out[rt.Version] = append(out[rt.Version], rt.Method+" "+rt.Path)
+	}
+	for _, routes := range out {
+		sort.Strings(routes)
+	}
+	return out
+}
@@ -0,0 +1,168 @@
+package neon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type UserServiceV1 struct {
+	Module  `base:"/users" v:"1"`
+	getUser Get `url:"/{id}"`
+}
+
+func (s UserServiceV1) GetUser(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("v1 " + r.PathValue("id")))
+}
+
+type UserServiceV2 struct {
+	Module  `base:"/users" v:"2"`
+	getUser Get `url:"/{id}"`
+}
+
+func (s UserServiceV2) GetUser(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("v2 " + r.PathValue("id")))
+}
+
+func newVersionedApp(strategy VersionStrategy) *App {
+	app := New()
+	app.Versioning = strategy
+	app.AddService(&UserServiceV1{})
+	app.AddService(&UserServiceV2{})
+	return app
+}
+
+func serve(app *App, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func TestPathVersioning(t *testing.T) {
+	app := newVersionedApp(PathVersioning)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/v1/users/alice", "v1 alice"},
+		{"/v2/users/alice", "v2 alice"},
+		{"/users/alice", "v1 alice"}, // default version fallback
+	}
+
+	for _, test := range tests {
+		w := serve(app, httptest.NewRequest("GET", test.path, nil))
+		if w.Body.String() != test.expected {
+			t.Errorf("GET %s: expected '%s', got '%s'", test.path, test.expected, w.Body.String())
+		}
+	}
+
+	w := serve(app, httptest.NewRequest("GET", "/v3/users/alice", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown version, got %d", w.Code)
+	}
+}
+
+func TestHeaderVersioning(t *testing.T) {
+	app := newVersionedApp(HeaderVersioning)
+
+	tests := []struct {
+		header   string
+		expected string
+		code     int
+	}{
+		{"", "v1 bob", http.StatusOK},
+		{"2", "v2 bob", http.StatusOK},
+		{"v2", "v2 bob", http.StatusOK},
+		{"9", "", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/users/bob", nil)
+		if test.header != "" {
+			req.Header.Set("X-API-Version", test.header)
+		}
+		w := serve(app, req)
+		if w.Code != test.code {
+			t.Errorf("Version %q: expected status %d, got %d", test.header, test.code, w.Code)
+		}
+		if test.code == http.StatusOK && w.Body.String() != test.expected {
+			t.Errorf("Version %q: expected '%s', got '%s'", test.header, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestAcceptVersioning(t *testing.T) {
+	app := newVersionedApp(AcceptVersioning)
+	app.DefaultVersion = "2"
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"application/json; version=1", "v1 carol"},
+		{"text/html, application/json;version=2", "v2 carol"},
+		{"application/json", "v2 carol"}, // default version fallback
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/users/carol", nil)
+		req.Header.Set("Accept", test.accept)
+		w := serve(app, req)
+		if w.Body.String() != test.expected {
+			t.Errorf("Accept %q: expected '%s', got '%s'", test.accept, test.expected, w.Body.String())
+		}
+	}
+}
+
+func TestVersioningSettingsApplyOnBuild(t *testing.T) {
+	app := newVersionedApp(HeaderVersioning)
+	if err := app.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// The built table keeps the settings it was built with
+	app.VersionHeader = "X-Version"
+	app.DefaultVersion = "2"
+	req := httptest.NewRequest("GET", "/users/bob", nil)
+	req.Header.Set("X-API-Version", "2")
+	if w := serve(app, req); w.Body.String() != "v2 bob" {
+		t.Errorf("Expected 'v2 bob' before rebuilding, got '%s'", w.Body.String())
+	}
+	if w := serve(app, httptest.NewRequest("GET", "/users/bob", nil)); w.Body.String() != "v1 bob" {
+		t.Errorf("Expected 'v1 bob' before rebuilding, got '%s'", w.Body.String())
+	}
+
+	app.Build()
+	if w := serve(app, req); w.Body.String() != "v2 bob" {
+		t.Errorf("Expected 'v2 bob' as the new default, got '%s'", w.Body.String())
+	}
+	req = httptest.NewRequest("GET", "/users/bob", nil)
+	req.Header.Set("X-Version", "1")
+	if w := serve(app, req); w.Body.String() != "v1 bob" {
+		t.Errorf("Expected the new header to be read, got '%s'", w.Body.String())
+	}
+}
+
+func TestVersionedRoutes(t *testing.T) {
+	app := newVersionedApp(PathVersioning)
+
+	expected := map[string][]string{
+		"1": {"GET /users/{id}", "GET /v1/users/{id}"},
+		"2": {"GET /v2/users/{id}"},
+	}
+
+	if got := app.VersionedRoutes(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	tests := map[string]string{"1": "1", "v2": "2", "V3": "3", " 4 ": "4", "v": "v"}
+	for in, expected := range tests {
+		if got := normalizeVersion(in); got != expected {
+			t.Errorf("normalizeVersion(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}